@@ -0,0 +1,658 @@
+// Command passwordgenerator generates a password from the command line or
+// interactively.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Guigui14460/Simple-Password-Generator/passwordgenerator"
+)
+
+// promptTries is the number of times a question is asked before giving up.
+const promptTries = 3
+
+// Default parameters of the password when only some of them are given by flags.
+const (
+	defaultLength  = 12
+	defaultDigits  = 2
+	defaultSymbols = 2
+)
+
+// cliOptions are the options of the command.
+type cliOptions struct {
+	params        passwordgenerator.GenerateConfig
+	interactive   bool
+	count         int
+	countGiven    bool
+	confirm       bool
+	charsetFile   string
+	table         bool
+	entropy       bool
+	strength      bool
+	jsonOutput    bool
+	selfTest      bool
+	blocklistFile string
+}
+
+var (
+	// errUsage is the error returned by parseArgs when the number of arguments is wrong.
+	errUsage = errors.New("wrong number of arguments")
+	// errFlag is the error returned by parseFlags when a flag is invalid, the
+	// flag package having already reported it.
+	errFlag = errors.New("invalid flag")
+	// errNotConfirmed is the error returned by run when the user did not
+	// retype the password.
+	errNotConfirmed = errors.New("the password was not confirmed")
+	// errAnswer is the error returned by run when the user did not answer
+	// whether to generate again.
+	errAnswer = errors.New("invalid answer")
+)
+
+/*
+Function which asks the user for a whole number, asking again on invalid input
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+		label (string): question shown to the user
+
+	Returns:
+	--------
+		int64, error - number and the error if no valid number was given after promptTries tries
+*/
+func promptInt(scanner *bufio.Scanner, label string) (int64, error) {
+	for try := 1; ; try++ {
+		text, err := prompt(scanner, label)
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err == nil {
+			return n, nil
+		}
+		if try == promptTries {
+			return 0, fmt.Errorf("%q is not a whole number", text)
+		}
+		print("please enter a whole number\n")
+	}
+}
+
+/*
+Function which asks the user for a boolean, asking again on invalid input
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+		label (string): question shown to the user
+
+	Returns:
+	--------
+		bool, error - boolean and the error if no valid boolean was given after promptTries tries
+*/
+func promptBool(scanner *bufio.Scanner, label string) (bool, error) {
+	for try := 1; ; try++ {
+		text, err := prompt(scanner, label)
+		if err != nil {
+			return false, err
+		}
+		b, err := strconv.ParseBool(text)
+		if err == nil {
+			return b, nil
+		}
+		if try == promptTries {
+			return false, fmt.Errorf("%q is not true or false", text)
+		}
+		print("please enter true or false\n")
+	}
+}
+
+/*
+Function which shows a question and reads the answer of the user
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+		label (string): question shown to the user
+
+	Returns:
+	--------
+		string, error - answer without surrounding spaces and the error if the input was closed
+*/
+func prompt(scanner *bufio.Scanner, label string) (string, error) {
+	print(label)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.ErrUnexpectedEOF
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+/*
+Function which asks the user to retype the password to be sure it was recorded
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+		password (string): password to retype
+		maxTries (int): number of tries given to the user
+
+	Returns:
+	--------
+		bool - true if the user retyped the password before running out of tries
+*/
+func confirmPassword(scanner *bufio.Scanner, password string, maxTries int) bool {
+	for try := 1; try <= maxTries; try++ {
+		print("Retype the password to confirm you recorded it : ")
+		if !scanner.Scan() {
+			return false
+		}
+		if scanner.Text() == password {
+			return true
+		}
+		print("The passwords do not match (", maxTries-try, " tries left)\n")
+	}
+	return false
+}
+
+/*
+Function which reads a blocklist file
+	Each non-empty line is a banned substring, the duplicates (ignoring the
+	case) being removed.
+
+	Parameters:
+	-----------
+		path (string): path of the file
+
+	Returns:
+	--------
+		[]string, error - banned substrings and the error if the file was not read
+*/
+func loadBlocklist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var banned []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		key := strings.ToLower(word)
+		if word != "" && !seen[key] {
+			seen[key] = true
+			banned = append(banned, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return banned, nil
+}
+
+/*
+Function which renders passwords in an aligned ASCII table
+	Parameters:
+	-----------
+		passwords ([]string): passwords of the rows, numbered from 1
+		entropies ([]float64): entropy in bits of each password, nil to omit the column
+
+	Returns:
+	--------
+		string - table, one line per row and per border
+*/
+func renderTable(passwords []string, entropies []float64) string {
+	header := []string{"#", "Password"}
+	if entropies != nil {
+		header = append(header, "Entropy")
+	}
+	rows := [][]string{header}
+	for i, pwd := range passwords {
+		row := []string{strconv.Itoa(i + 1), pwd}
+		if entropies != nil {
+			row = append(row, strconv.FormatFloat(entropies[i], 'f', 2, 64))
+		}
+		rows = append(rows, row)
+	}
+
+	// Compute the width of the columns
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for c, cell := range row {
+			widths[c] = max(widths[c], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var b strings.Builder
+	border := func() {
+		for _, w := range widths {
+			b.WriteString("+" + strings.Repeat("-", w+2))
+		}
+		b.WriteString("+\n")
+	}
+	border()
+	for r, row := range rows {
+		for c, cell := range row {
+			padding := strings.Repeat(" ", widths[c]-utf8.RuneCountInString(cell))
+			// Align the numbers to the right
+			if c != 1 && r > 0 {
+				cell = padding + cell
+			} else {
+				cell += padding
+			}
+			b.WriteString("| " + cell + " ")
+		}
+		b.WriteString("|\n")
+		if r == 0 {
+			border()
+		}
+	}
+	border()
+	return b.String()
+}
+
+/*
+Function which converts the positionned arguments of the command
+	Parameters:
+	-----------
+		args ([]string): length, number of digits, number of symbols and optionally allow_uppercase and allow_repeat
+
+	Returns:
+	--------
+		passwordgenerator.GenerateConfig, error - parameters of the password and the error if an argument is invalid
+			Note: errUsage is returned if the number of arguments is wrong
+*/
+func parseArgs(args []string) (passwordgenerator.GenerateConfig, error) {
+	params := passwordgenerator.GenerateConfig{AllowUppercase: true, AllowRepeat: true}
+	if len(args) != 3 && len(args) != 5 {
+		return params, errUsage
+	}
+
+	// Convert the arguments
+	numbers := []struct {
+		name  string
+		value *int
+	}{
+		{"length", &params.Length},
+		{"number of digits", &params.NumDigits},
+		{"number of symbols", &params.NumSymbols},
+	}
+	for i, number := range numbers {
+		n, err := strconv.Atoi(args[i])
+		if err != nil {
+			return params, fmt.Errorf("invalid %s: %q is not a whole number", number.name, args[i])
+		}
+		*number.value = n
+	}
+	if len(args) == 5 {
+		var err error
+		if params.AllowUppercase, err = strconv.ParseBool(args[3]); err != nil {
+			return params, fmt.Errorf("invalid allow_uppercase: %q is not true or false", args[3])
+		}
+		if params.AllowRepeat, err = strconv.ParseBool(args[4]); err != nil {
+			return params, fmt.Errorf("invalid allow_repeat: %q is not true or false", args[4])
+		}
+	}
+	return params, nil
+}
+
+/*
+Function which parses the flags and the positionned arguments of the command
+	The generation flags (-length, -digits, -symbols, -upper and -repeat) and
+	the positionned arguments are exclusive. Without any of them, the
+	password is asked interactively.
+
+	Parameters:
+	-----------
+		args ([]string): arguments of the command, without the program name
+
+	Returns:
+	--------
+		cliOptions, error - options of the command and the error if an argument is invalid
+			Note: flag.ErrHelp is returned if -help was given, errFlag if a flag is invalid
+			and errUsage if the number of positionned arguments is wrong
+*/
+func parseFlags(args []string) (cliOptions, error) {
+	opts := cliOptions{
+		params: passwordgenerator.GenerateConfig{
+			Length:         defaultLength,
+			NumDigits:      defaultDigits,
+			NumSymbols:     defaultSymbols,
+			AllowUppercase: true,
+			AllowRepeat:    true,
+		},
+	}
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage : %s [flags] [<length> <number_of_digits> <number_of_symbols> [<allow_uppercase:(false|true)> <allow_repeat:(false|true)>]]\n", fs.Name())
+		fmt.Fprintln(fs.Output(), "Without -length, -digits, -symbols, -upper, -repeat nor arguments, the parameters are asked interactively.")
+		fs.PrintDefaults()
+	}
+	fs.IntVar(&opts.params.Length, "length", opts.params.Length, "total number of characters")
+	fs.IntVar(&opts.params.NumDigits, "digits", opts.params.NumDigits, "number of digits to include")
+	fs.IntVar(&opts.params.NumSymbols, "symbols", opts.params.NumSymbols, "number of symbols to include")
+	fs.BoolVar(&opts.params.AllowUppercase, "upper", opts.params.AllowUppercase, "include uppercase letters")
+	fs.BoolVar(&opts.params.AllowRepeat, "repeat", opts.params.AllowRepeat, "allow repeat characters")
+	fs.IntVar(&opts.count, "count", 1, "number of passwords to generate")
+	fs.BoolVar(&opts.confirm, "confirm", false, "ask to retype the password to confirm it was recorded")
+	fs.StringVar(&opts.charsetFile, "charset-file", "", "`path` of a file defining the lower=, upper=, digits= and symbols= character sets")
+	fs.BoolVar(&opts.table, "table", false, "show the passwords in a table (with an entropy column if -entropy is given)")
+	fs.BoolVar(&opts.entropy, "entropy", false, "show the entropy of the passwords in bits (in a column with -table)")
+	fs.BoolVar(&opts.strength, "strength", false, "show the strength of the passwords (weak, fair, strong or very strong)")
+	fs.BoolVar(&opts.jsonOutput, "json", false, "print the password as a JSON object (an array of objects with -count)")
+	fs.BoolVar(&opts.selfTest, "selftest", false, "verify that the random source works, then quit")
+	fs.StringVar(&opts.blocklistFile, "blocklist", "", "`path` of a file of banned substrings, one per line")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return opts, err
+		}
+		return opts, errFlag
+	}
+
+	// Verify the options
+	if opts.count < 1 {
+		return opts, fmt.Errorf("invalid count: %d is not positive", opts.count)
+	}
+	if opts.confirm && opts.count > 1 {
+		return opts, errors.New("-confirm can only be used with a single password")
+	}
+	if opts.jsonOutput && opts.table {
+		return opts, errors.New("-json and -table cannot be used together")
+	}
+
+	// Find how the parameters of the password are given
+	generationFlags := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "length", "digits", "symbols", "upper", "repeat":
+			generationFlags = true
+		case "count":
+			opts.countGiven = true
+		}
+	})
+	switch {
+	case fs.NArg() > 0 && generationFlags:
+		return opts, errors.New("the parameters must be given either by flags or by positionned arguments")
+	case fs.NArg() > 0:
+		params, err := parseArgs(fs.Args())
+		if err != nil {
+			return opts, err
+		}
+		opts.params = params
+	case !generationFlags:
+		opts.interactive = true
+	}
+	return opts, nil
+}
+
+/*
+Function which asks the user for the parameters of the password
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+
+	Returns:
+	--------
+		passwordgenerator.GenerateConfig, error - parameters of the password and the error if an answer is invalid
+*/
+func askParams(scanner *bufio.Scanner) (passwordgenerator.GenerateConfig, error) {
+	var params passwordgenerator.GenerateConfig
+	questions := []struct {
+		label string
+		value *int
+	}{
+		{"Length of the password : ", &params.Length},
+		{"Total number of digits : ", &params.NumDigits},
+		{"Total number of symbols : ", &params.NumSymbols},
+	}
+	for _, question := range questions {
+		n, err := promptInt(scanner, question.label)
+		if err != nil {
+			return params, err
+		}
+		*question.value = int(n)
+	}
+	var err error
+	params.AllowUppercase, err = promptBool(scanner, "Activate the uppercase (false for NO, true for YES) : ")
+	if err != nil {
+		return params, err
+	}
+	params.AllowRepeat, err = promptBool(scanner, "Activate the character repeat (false for NO, true for YES) : ")
+	return params, err
+}
+
+/*
+Function which asks the user for the number of passwords to generate
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+
+	Returns:
+	--------
+		int, error - number of passwords and the error if no valid number was given
+*/
+func askCount(scanner *bufio.Scanner) (int, error) {
+	n, err := promptInt(scanner, "How many passwords ? ")
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("invalid count: %d is not positive", n)
+	}
+	return int(n), nil
+}
+
+/*
+Function which asks the user whether to generate other passwords, asking again on invalid input
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+
+	Returns:
+	--------
+		bool, error - true to generate again and the error if no valid answer was given after promptTries tries
+*/
+func askAgain(scanner *bufio.Scanner) (bool, error) {
+	for try := 1; ; try++ {
+		text, err := prompt(scanner, "Generate another with the same settings ? (y/n) ")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(text) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		if try == promptTries {
+			return false, fmt.Errorf("%q is not y or n", text)
+		}
+		print("please enter y or n\n")
+	}
+}
+
+/*
+Function which writes the generated passwords in the format chosen by the options
+	By default, the passwords are written one per line, followed by their
+	entropy and strength if asked. With -table, the entropy is a column of
+	the table if asked. With -json, a single password is written
+	as a JSON object and several passwords as a JSON array.
+
+	Parameters:
+	-----------
+		w (io.Writer): writer of the output
+		gen (*passwordgenerator.Generator): generator of the passwords
+		passwords ([]string): generated passwords
+		params (passwordgenerator.GenerateConfig): parameters of the passwords
+		opts (cliOptions): options of the command
+
+	Returns:
+	--------
+		error - error if the output was not written
+*/
+func writePasswords(w io.Writer, gen *passwordgenerator.Generator, passwords []string, params passwordgenerator.GenerateConfig, opts cliOptions) error {
+	switch {
+	case opts.jsonOutput:
+		results := make([]passwordgenerator.PasswordResult, len(passwords))
+		for i, pwd := range passwords {
+			results[i] = gen.Result(pwd, params)
+		}
+		enc := json.NewEncoder(w)
+		if len(results) == 1 {
+			return enc.Encode(results[0])
+		}
+		return enc.Encode(results)
+	case opts.table:
+		var entropies []float64
+		if opts.entropy {
+			entropies = make([]float64, len(passwords))
+			for i := range entropies {
+				entropies[i] = gen.Entropy(params)
+			}
+		}
+		_, err := io.WriteString(w, renderTable(passwords, entropies))
+		return err
+	}
+
+	var b strings.Builder
+	for _, pwd := range passwords {
+		b.WriteString(pwd + "\n")
+	}
+	if opts.entropy {
+		fmt.Fprintf(&b, "Entropy : %.2f bits\n", gen.Entropy(params))
+	}
+	if opts.strength {
+		fmt.Fprintf(&b, "Strength : %s\n", gen.ClassifyStrength(params))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+/*
+Function which generates and writes the passwords, again with the same parameters while the user asks for it
+	Parameters:
+	-----------
+		scanner (*bufio.Scanner): scanner reading the user input
+		w (io.Writer): writer of the passwords
+		gen (*passwordgenerator.Generator): generator of the passwords
+		params (passwordgenerator.GenerateConfig): parameters of the passwords
+		opts (cliOptions): options of the command (asked again only in interactive mode)
+
+	Returns:
+	--------
+		error - error if the passwords were not generated, written or confirmed
+*/
+func run(scanner *bufio.Scanner, w io.Writer, gen *passwordgenerator.Generator, params passwordgenerator.GenerateConfig, opts cliOptions) error {
+	for {
+		passwords, err := gen.GenerateN(opts.count, params.Length, params.NumDigits, params.NumSymbols, params.AllowUppercase, params.AllowRepeat)
+		if err != nil {
+			return err
+		}
+
+		// Show the generated passwords
+		if err = writePasswords(w, gen, passwords, params, opts); err != nil {
+			return err
+		}
+		if opts.confirm && !confirmPassword(scanner, passwords[0], 3) {
+			return errNotConfirmed
+		}
+
+		// Generate again with the same parameters if the user wants to
+		if !opts.interactive {
+			return nil
+		}
+		again, err := askAgain(scanner)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", errAnswer, err)
+		}
+		if !again {
+			return nil
+		}
+	}
+}
+
+func main() {
+	// Initialize variables
+	scanner := bufio.NewScanner(os.Stdin)
+
+	// Get the options and the parameters of the password
+	opts, err := parseFlags(os.Args[1:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	case errors.Is(err, errFlag):
+		os.Exit(2)
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, "Error : 3 or 5 positionned arguments are expected, see -help")
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Error :", err)
+		os.Exit(2)
+	}
+	// Verify the random source
+	if opts.selfTest {
+		if err = passwordgenerator.NewGenerator(nil).SelfTest(); err != nil {
+			fmt.Fprintln(os.Stderr, "Self-test : FAILED :", err)
+			os.Exit(1)
+		}
+		fmt.Println("Self-test : OK")
+		return
+	}
+
+	params := opts.params
+	if opts.interactive {
+		params, err = askParams(scanner)
+		if err == nil && !opts.countGiven && !opts.confirm {
+			opts.count, err = askCount(scanner)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error :", err)
+			os.Exit(2)
+		}
+	}
+
+	// Generate the passwords
+	var banned []string
+	if opts.blocklistFile != "" {
+		banned, err = loadBlocklist(opts.blocklistFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error :", err)
+			os.Exit(2)
+		}
+	}
+	gen := passwordgenerator.NewGenerator(nil, passwordgenerator.WithBlocklist(banned))
+	if opts.charsetFile != "" {
+		gen, err = passwordgenerator.NewGeneratorFromCharsetFile(opts.charsetFile, passwordgenerator.WithBlocklist(banned))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error :", err)
+			os.Exit(2)
+		}
+	}
+	err = run(scanner, os.Stdout, gen, params, opts)
+	switch {
+	case opts.blocklistFile != "" && errors.Is(err, passwordgenerator.ErrCannotSatisfy) && !errors.Is(err, passwordgenerator.ErrTooFewDistinct):
+		// Only the blocklist can make a single password unsatisfiable
+		fmt.Fprintln(os.Stderr, "Error : no password avoiding the blocklist was found")
+		os.Exit(4)
+	case errors.Is(err, errNotConfirmed):
+		fmt.Println("The password was not confirmed, please generate a new one")
+		os.Exit(1)
+	case errors.Is(err, errAnswer):
+		fmt.Fprintln(os.Stderr, "Error :", err)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Error :", err)
+		os.Exit(1)
+	}
+}
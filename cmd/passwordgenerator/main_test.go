@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Guigui14460/Simple-Password-Generator/passwordgenerator"
+)
+
+func TestRunRegenerates(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("y\ny\nn\n"))
+	var out strings.Builder
+	params := passwordgenerator.GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	opts := cliOptions{params: params, interactive: true, count: 1}
+	if err := run(scanner, &out, passwordgenerator.NewGenerator(nil), params, opts); err != nil {
+		t.Fatal(err)
+	}
+	if passwords := strings.Fields(out.String()); len(passwords) != 3 {
+		t.Fatalf("got %d passwords (%q), want 3", len(passwords), out.String())
+	}
+}
+
+func TestRunNotInteractive(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("y\n"))
+	var out strings.Builder
+	params := passwordgenerator.GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	opts := cliOptions{params: params, count: 2}
+	if err := run(scanner, &out, passwordgenerator.NewGenerator(nil), params, opts); err != nil {
+		t.Fatal(err)
+	}
+	if passwords := strings.Fields(out.String()); len(passwords) != 2 {
+		t.Fatalf("got %d passwords (%q), want 2", len(passwords), out.String())
+	}
+}
+
+func TestRunBlocklistErrors(t *testing.T) {
+	input := &passwordgenerator.GeneratorInput{LowerLetters: "ab"}
+	params := passwordgenerator.GenerateConfig{Length: 3, AllowRepeat: true}
+
+	// The blocklist bans every password
+	gen := passwordgenerator.NewGenerator(input, passwordgenerator.WithBlocklist([]string{"a", "b"}))
+	err := run(bufio.NewScanner(strings.NewReader("")), io.Discard, gen, params, cliOptions{params: params, count: 1})
+	if !errors.Is(err, passwordgenerator.ErrCannotSatisfy) || errors.Is(err, passwordgenerator.ErrTooFewDistinct) {
+		t.Errorf("run(unsatisfiable blocklist) error = %v, want %v only", err, passwordgenerator.ErrCannotSatisfy)
+	}
+
+	// The blocklist leaves a single password, so a second one collides
+	gen = passwordgenerator.NewGenerator(input, passwordgenerator.WithBlocklist([]string{"a"}))
+	err = run(bufio.NewScanner(strings.NewReader("")), io.Discard, gen, params, cliOptions{params: params, count: 2})
+	if !errors.Is(err, passwordgenerator.ErrTooFewDistinct) {
+		t.Errorf("run(count above the distinct passwords) error = %v, want %v", err, passwordgenerator.ErrTooFewDistinct)
+	}
+}
+
+func TestConfirmPassword(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  bool
+	}{
+		{"s3cret!\n", true},
+		{"wrong\nS3cret!\ns3cret!\n", true},
+		{"wrong\nwrong\nwrong\ns3cret!\n", false},
+		{"wrong\n", false},
+	} {
+		scanner := bufio.NewScanner(strings.NewReader(tt.input))
+		if got := confirmPassword(scanner, "s3cret!", 3); got != tt.want {
+			t.Errorf("confirmPassword(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPromptInt(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("twelve\n 12 \n"))
+	if n, err := promptInt(scanner, "Length : "); n != 12 || err != nil {
+		t.Errorf("promptInt(bad then good) = %d, %v, want 12, nil", n, err)
+	}
+	scanner = bufio.NewScanner(strings.NewReader("a\nb\nc\n12\n"))
+	if _, err := promptInt(scanner, "Length : "); err == nil {
+		t.Error("promptInt(3 bad answers) returned no error")
+	}
+	scanner = bufio.NewScanner(strings.NewReader(""))
+	if _, err := promptInt(scanner, "Length : "); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("promptInt(no input) error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestPromptBool(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("yes please\nfalse\n"))
+	if b, err := promptBool(scanner, "Uppercase : "); b || err != nil {
+		t.Errorf("promptBool(bad then good) = %v, %v, want false, nil", b, err)
+	}
+	scanner = bufio.NewScanner(strings.NewReader("a\nb\nc\ntrue\n"))
+	if _, err := promptBool(scanner, "Uppercase : "); err == nil {
+		t.Error("promptBool(3 bad answers) returned no error")
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	want := "" +
+		"+---+------------+---------+\n" +
+		"| # | Password   | Entropy |\n" +
+		"+---+------------+---------+\n" +
+		"| 1 | abc        |   12.50 |\n" +
+		"| 2 | password10 |   66.44 |\n" +
+		"+---+------------+---------+\n"
+	if got := renderTable([]string{"abc", "password10"}, []float64{12.5, 66.4389}); got != want {
+		t.Errorf("renderTable() =\n%s\nwant\n%s", got, want)
+	}
+	want = "" +
+		"+---+----------+\n" +
+		"| # | Password |\n" +
+		"+---+----------+\n" +
+		"| 1 | x        |\n" +
+		"+---+----------+\n"
+	if got := renderTable([]string{"x"}, nil); got != want {
+		t.Errorf("renderTable(no entropy) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	params, err := parseArgs([]string{"16", "3", "2", "false", "true"})
+	want := passwordgenerator.GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 2, AllowUppercase: false, AllowRepeat: true}
+	if err != nil || params != want {
+		t.Errorf("parseArgs(valid) = %+v, %v, want %+v, nil", params, err, want)
+	}
+	for _, args := range [][]string{
+		{"16", "3"},
+		{"16", "3", "2", "false"},
+	} {
+		if _, err := parseArgs(args); !errors.Is(err, errUsage) {
+			t.Errorf("parseArgs(%q) error = %v, want %v", args, err, errUsage)
+		}
+	}
+	for _, args := range [][]string{
+		{"sixteen", "3", "2"},
+		{"16", "3", "2.5"},
+		{"16", "3", "2", "maybe", "true"},
+	} {
+		if _, err := parseArgs(args); err == nil || errors.Is(err, errUsage) {
+			t.Errorf("parseArgs(%q) error = %v, want an invalid value error", args, err)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	defaults := passwordgenerator.GenerateConfig{Length: defaultLength, NumDigits: defaultDigits, NumSymbols: defaultSymbols, AllowUppercase: true, AllowRepeat: true}
+	opts, err := parseFlags(nil)
+	if err != nil || !opts.interactive || opts.params != defaults || opts.count != 1 {
+		t.Errorf("parseFlags(none) = %+v, %v, want the interactive mode with the defaults", opts, err)
+	}
+
+	opts, err = parseFlags([]string{"-length", "20", "-upper=false", "-count", "3"})
+	want := defaults
+	want.Length, want.AllowUppercase = 20, false
+	if err != nil || opts.interactive || opts.params != want || opts.count != 3 || !opts.countGiven {
+		t.Errorf("parseFlags(overrides) = %+v, %v, want %+v and 3 passwords", opts, err, want)
+	}
+
+	opts, err = parseFlags([]string{"-count", "2", "10", "1", "1"})
+	want = passwordgenerator.GenerateConfig{Length: 10, NumDigits: 1, NumSymbols: 1, AllowUppercase: true, AllowRepeat: true}
+	if err != nil || opts.interactive || opts.params != want || opts.count != 2 {
+		t.Errorf("parseFlags(positional) = %+v, %v, want %+v", opts, err, want)
+	}
+
+	for _, args := range [][]string{
+		{"-length", "10", "10", "1", "1"},
+		{"-count", "0"},
+		{"-confirm", "-count", "2"},
+		{"-json", "-table"},
+	} {
+		if _, err := parseFlags(args); err == nil {
+			t.Errorf("parseFlags(%q) returned no error", args)
+		}
+	}
+}
+
+func TestWritePasswords(t *testing.T) {
+	gen := passwordgenerator.NewGenerator(nil)
+	params := passwordgenerator.GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	passwords, err := gen.GenerateN(4, params.Length, params.NumDigits, params.NumSymbols, params.AllowUppercase, params.AllowRepeat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := writePasswords(&out, gen, passwords, params, cliOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); !slices.Equal(got, passwords) {
+		t.Errorf("writePasswords() lines = %q, want %q", got, passwords)
+	}
+
+	out.Reset()
+	if err := writePasswords(&out, gen, passwords, params, cliOptions{jsonOutput: true}); err != nil {
+		t.Fatal(err)
+	}
+	var results []passwordgenerator.PasswordResult
+	if err := json.Unmarshal([]byte(out.String()), &results); err != nil || len(results) != len(passwords) {
+		t.Errorf("writePasswords(-json) = %s, want an array of %d results (%v)", out.String(), len(passwords), err)
+	}
+
+	out.Reset()
+	if err := writePasswords(&out, gen, passwords[:1], params, cliOptions{jsonOutput: true}); err != nil {
+		t.Fatal(err)
+	}
+	var result passwordgenerator.PasswordResult
+	if err := json.Unmarshal([]byte(out.String()), &result); err != nil || result.Password != passwords[0] {
+		t.Errorf("writePasswords(-json, 1 password) = %s, want the object of %q (%v)", out.String(), passwords[0], err)
+	}
+}
+
+func TestWritePasswordsTable(t *testing.T) {
+	gen := passwordgenerator.NewGenerator(nil)
+	params := passwordgenerator.GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	passwords := []string{"abcdefgh12!?", "ijklmnop34#$"}
+
+	var out strings.Builder
+	if err := writePasswords(&out, gen, passwords, params, cliOptions{table: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := renderTable(passwords, nil); out.String() != want {
+		t.Errorf("writePasswords(-table) =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := writePasswords(&out, gen, passwords, params, cliOptions{table: true, entropy: true}); err != nil {
+		t.Fatal(err)
+	}
+	entropy := gen.Entropy(params)
+	if want := renderTable(passwords, []float64{entropy, entropy}); out.String() != want {
+		t.Errorf("writePasswords(-table -entropy) =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("acme\n\n  Ab \nACME\nab\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	banned, err := loadBlocklist(path)
+	if want := []string{"acme", "Ab"}; err != nil || !slices.Equal(banned, want) {
+		t.Errorf("loadBlocklist() = %q, %v, want %q, nil", banned, err, want)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/Guigui14460/Simple-Password-Generator
+
+go 1.24
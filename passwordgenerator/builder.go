@@ -0,0 +1,180 @@
+package passwordgenerator
+
+// PasswordBuilder keeps the parameters of a password edited step by step,
+// e.g. by a user interface toggling the options live.
+type PasswordBuilder struct {
+	gen     *Generator
+	cfg     GenerateConfig
+	preview string
+}
+
+/*
+Function which creates a builder starting from the given parameters.
+	Parameters:
+	-----------
+		g (*Generator): generator of the passwords (the default one if nil)
+		cfg (GenerateConfig): initial parameters
+
+	Returns:
+	--------
+		*PasswordBuilder, error - builder and the error if no password can be generated with the parameters
+*/
+func NewPasswordBuilder(g *Generator, cfg GenerateConfig) (*PasswordBuilder, error) {
+	if g == nil {
+		g = NewGenerator(nil)
+	}
+	b := &PasswordBuilder{gen: g}
+	if err := b.set(cfg); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+/*
+Function which returns the current parameters.
+	Method of PasswordBuilder type
+
+	Returns:
+	--------
+		GenerateConfig - parameters of the next passwords
+*/
+func (b *PasswordBuilder) Config() GenerateConfig {
+	return b.cfg
+}
+
+/*
+Function which changes the total number of characters.
+	Method of PasswordBuilder type
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+
+	Returns:
+	--------
+		error - error if no password can be generated with this length (the length is then unchanged)
+*/
+func (b *PasswordBuilder) SetLength(length int) error {
+	cfg := b.cfg
+	cfg.Length = length
+	return b.set(cfg)
+}
+
+/*
+Function which changes the number of digits.
+	Method of PasswordBuilder type
+
+	Parameters:
+	-----------
+		numDigits (int): number of digits to include
+
+	Returns:
+	--------
+		error - error if no password can be generated with this number (the number is then unchanged)
+*/
+func (b *PasswordBuilder) SetDigits(numDigits int) error {
+	cfg := b.cfg
+	cfg.NumDigits = numDigits
+	return b.set(cfg)
+}
+
+/*
+Function which changes the number of symbols.
+	Method of PasswordBuilder type
+
+	Parameters:
+	-----------
+		numSymbols (int): number of symbols to include
+
+	Returns:
+	--------
+		error - error if no password can be generated with this number (the number is then unchanged)
+*/
+func (b *PasswordBuilder) SetSymbols(numSymbols int) error {
+	cfg := b.cfg
+	cfg.NumSymbols = numSymbols
+	return b.set(cfg)
+}
+
+/*
+Function which includes or removes the uppercase letters.
+	Method of PasswordBuilder type
+
+	Returns:
+	--------
+		error - error if no password can be generated once toggled (the option is then unchanged)
+*/
+func (b *PasswordBuilder) ToggleUpper() error {
+	cfg := b.cfg
+	cfg.AllowUppercase = !cfg.AllowUppercase
+	return b.set(cfg)
+}
+
+/*
+Function which allows or forbids the repeat characters.
+	Method of PasswordBuilder type
+
+	Returns:
+	--------
+		error - error if no password can be generated once toggled (the option is then unchanged)
+*/
+func (b *PasswordBuilder) ToggleRepeat() error {
+	cfg := b.cfg
+	cfg.AllowRepeat = !cfg.AllowRepeat
+	return b.set(cfg)
+}
+
+/*
+Function which generates a new password to show to the user.
+	Method of PasswordBuilder type
+	Each call gives another password. The last one is kept for Build as long
+	as the parameters do not change.
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (b *PasswordBuilder) Preview() (string, error) {
+	pwd, err := b.gen.generate(b.cfg)
+	if err != nil {
+		return "", err
+	}
+	b.preview = pwd
+	return pwd, nil
+}
+
+/*
+Function which returns the final password.
+	Method of PasswordBuilder type
+
+	Returns:
+	--------
+		string, error - last previewed password (a new one if the parameters changed since) and the error if the password was not generated
+*/
+func (b *PasswordBuilder) Build() (string, error) {
+	if b.preview != "" {
+		return b.preview, nil
+	}
+	return b.gen.generate(b.cfg)
+}
+
+/*
+Function which replaces the parameters if a password can be generated with them.
+	Method of PasswordBuilder type
+
+	Parameters:
+	-----------
+		cfg (GenerateConfig): new parameters
+
+	Returns:
+	--------
+		error - error if no password can be generated with the parameters (the parameters are then unchanged)
+*/
+func (b *PasswordBuilder) set(cfg GenerateConfig) error {
+	if _, err := b.gen.charClasses(cfg); err != nil {
+		return err
+	}
+	b.cfg = cfg
+	b.preview = ""
+	return nil
+}
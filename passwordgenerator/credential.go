@@ -0,0 +1,100 @@
+package passwordgenerator
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CredentialIterations is the number of PBKDF2-SHA256 iterations of the
+	// verifiers created by GenerateCredential (as advised by OWASP in 2023).
+	CredentialIterations = 600000
+	// credentialKeySize is the number of bytes of the hashes of the verifiers.
+	credentialKeySize = 32
+	// maxCredentialIterations and maxCredentialKeySize bound the work asked
+	// by a verifier, so a crafted one cannot make VerifyCredential hang.
+	maxCredentialIterations = 4 * CredentialIterations
+	maxCredentialKeySize    = 4 * credentialKeySize
+)
+
+// ErrInvalidVerifier is the error returned when a verifier does not have the
+// $pbkdf2-sha256$i=<iterations>$<salt>$<hash> format.
+var ErrInvalidVerifier = errors.New("invalid credential verifier")
+
+/*
+Function to generate a password along with a verifier to store instead of it.
+	Method of Generator type
+	The verifier is a PHC string $pbkdf2-sha256$i=<iterations>$<salt>$<hash>
+	(salt and hash in unpadded base64) which VerifyCredential checks a
+	password against, so the password itself never needs to be stored.
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, string, error - password, verifier and the error if they were not generated
+*/
+func (g *Generator) GenerateCredential(params GenerateConfig) (plaintext string, verifier string, err error) {
+	plaintext, err = g.generate(params)
+	if err != nil {
+		return "", "", err
+	}
+	salt := make([]byte, SaltSize)
+	if _, err = io.ReadFull(g.reader, salt); err != nil {
+		return "", "", err
+	}
+	hash, err := pbkdf2.Key(sha256.New, plaintext, salt, CredentialIterations, credentialKeySize)
+	if err != nil {
+		return "", "", err
+	}
+	verifier = fmt.Sprintf("$pbkdf2-sha256$i=%d$%s$%s", CredentialIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+	return plaintext, verifier, nil
+}
+
+/*
+Function which checks a password against a verifier created by GenerateCredential
+	Parameters:
+	-----------
+		plaintext (string): password to check
+		verifier (string): verifier of the expected password
+
+	Returns:
+	--------
+		bool, error - true if the password matches and the error if the verifier is invalid
+			Note: ErrInvalidVerifier is returned for more than 4 times the iterations or the hash size of GenerateCredential
+*/
+func VerifyCredential(plaintext, verifier string) (bool, error) {
+	// Split the $pbkdf2-sha256$i=...$salt$hash fields
+	fields := strings.Split(verifier, "$")
+	if len(fields) != 5 || fields[0] != "" || fields[1] != "pbkdf2-sha256" || !strings.HasPrefix(fields[2], "i=") {
+		return false, ErrInvalidVerifier
+	}
+	iterations, err := strconv.Atoi(strings.TrimPrefix(fields[2], "i="))
+	if err != nil || iterations < 1 || iterations > maxCredentialIterations {
+		return false, fmt.Errorf("%w: bad iteration count %q", ErrInvalidVerifier, fields[2])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(fields[3])
+	if err != nil || len(salt) == 0 {
+		return false, fmt.Errorf("%w: bad salt", ErrInvalidVerifier)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil || len(expected) == 0 || len(expected) > maxCredentialKeySize {
+		return false, fmt.Errorf("%w: bad hash", ErrInvalidVerifier)
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, plaintext, salt, iterations, len(expected))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(hash, expected) == 1, nil
+}
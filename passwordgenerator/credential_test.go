@@ -0,0 +1,37 @@
+package passwordgenerator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestVerifyCredential(t *testing.T) {
+	g := NewGenerator(nil)
+	plaintext, verifier, err := g.GenerateCredential(GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyCredential(plaintext, verifier); !ok || err != nil {
+		t.Errorf("VerifyCredential(plaintext) = %v, %v, want true, nil", ok, err)
+	}
+	if ok, err := VerifyCredential(plaintext+"x", verifier); ok || err != nil {
+		t.Errorf("VerifyCredential(other) = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestVerifyCredentialBoundsWork(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString(make([]byte, SaltSize))
+	hash := base64.RawStdEncoding.EncodeToString(make([]byte, credentialKeySize))
+	longHash := base64.RawStdEncoding.EncodeToString(make([]byte, maxCredentialKeySize+1))
+	for _, verifier := range []string{
+		fmt.Sprintf("$pbkdf2-sha256$i=%d$%s$%s", maxCredentialIterations+1, salt, hash),
+		"$pbkdf2-sha256$i=2147483647$" + salt + "$" + hash,
+		"$pbkdf2-sha256$i=1$" + salt + "$" + longHash,
+	} {
+		if _, err := VerifyCredential("password", verifier); !errors.Is(err, ErrInvalidVerifier) {
+			t.Errorf("VerifyCredential(%q) error = %v, want %v", verifier[:40], err, ErrInvalidVerifier)
+		}
+	}
+}
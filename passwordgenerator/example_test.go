@@ -0,0 +1,19 @@
+package passwordgenerator_test
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/Guigui14460/Simple-Password-Generator/passwordgenerator"
+)
+
+func Example() {
+	gen := passwordgenerator.NewGenerator(nil)
+	pwd, err := gen.Generate(16, 3, 2, true, false)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(utf8.RuneCountInString(pwd))
+	// Output: 16
+}
@@ -0,0 +1,73 @@
+package passwordgenerator
+
+import "fmt"
+
+// GenerateError is the error returned when the parameters of a password
+// cannot be satisfied. It carries the offending parameters and wraps one of
+// the sentinel errors (e.g. ErrDigitsExceedsAvailable), so errors.Is still
+// works and errors.As gives the details.
+type GenerateError struct {
+	// Reason describes the violated constraint, e.g. "requested 30 unique
+	// digits but only 10 available".
+	Reason string
+	// Length is the requested total number of characters.
+	Length int
+	// NumDigits is the requested number of digits.
+	NumDigits int
+	// NumSymbols is the requested number of symbols.
+	NumSymbols int
+	// PoolSize is the number of distinct characters available for the
+	// offending class (0 if no class is concerned).
+	PoolSize int
+	// Err is the sentinel error describing the kind of failure.
+	Err error
+}
+
+/*
+Function which returns the message of the error.
+	Method of GenerateError type
+
+	Returns:
+	--------
+		string - message of the sentinel error followed by the reason
+*/
+func (e *GenerateError) Error() string {
+	return e.Err.Error() + ": " + e.Reason
+}
+
+/*
+Function which returns the sentinel error wrapped by the error.
+	Method of GenerateError type
+
+	Returns:
+	--------
+		error - sentinel error
+*/
+func (e *GenerateError) Unwrap() error {
+	return e.Err
+}
+
+/*
+Function to create an error for a class requesting more unique characters than available.
+	Parameters:
+	-----------
+		err (error): sentinel error
+		params (GenerateConfig): parameters of the password
+		class (string): name of the class in the plural, e.g. "digits"
+		requested (int): number of characters of the class
+		poolSize (int): number of distinct characters of the class
+
+	Returns:
+	--------
+		*GenerateError - error with the parameters
+*/
+func exceedsAvailable(err error, params GenerateConfig, class string, requested, poolSize int) *GenerateError {
+	return &GenerateError{
+		Reason:     fmt.Sprintf("requested %d unique %s but only %d available", requested, class, poolSize),
+		Length:     params.Length,
+		NumDigits:  params.NumDigits,
+		NumSymbols: params.NumSymbols,
+		PoolSize:   poolSize,
+		Err:        err,
+	}
+}
@@ -0,0 +1,5319 @@
+// Package passwordgenerator generates random passwords, passphrases and codes
+// from configurable character sets, using crypto/rand by default.
+package passwordgenerator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"math/bits"
+	mathrand "math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// LowerLetters is the list of lowercase letters.
+	LowerLetters = "abcdefghijklmnopqrstuvwxyz"
+	// UpperLetters is the list of uppercase letters.
+	UpperLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// Digits is the list of permitted digits.
+	Digits = "0123456789"
+	// Symbols is the list of permitted symbols.
+	Symbols = "~!@#$%^&*()_+`-={}|[]\\:\"<>?,./"
+	// URLSafeSymbols is the list of symbols which are not escaped in URLs.
+	URLSafeSymbols = "-._~"
+	// QRAlphanumeric is the list of the 45 characters of the alphanumeric mode
+	// of the QR codes.
+	QRAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+	// SafeAlphabet is the list of uppercase letters and digits which cannot be
+	// confused with each other when read (no 0/O, 1/I/L).
+	SafeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	// AmbiguousChars is the list of letters and digits which are easily
+	// confused with each other when read (0/O/o, 1/I/l, 5/S), removed by
+	// GeneratorInput.ExcludeAmbiguous.
+	AmbiguousChars = "0Oo1Il5S"
+	// DefaultMaxAttempts is the default number of candidates drawn by the
+	// methods which regenerate a password until it satisfies a constraint.
+	DefaultMaxAttempts = 1000
+	// SaltSize is the number of bytes of the salts returned by GenerateSaltedToken.
+	SaltSize = 16
+	// DefaultTOTPSecretSize is the default number of bytes of the secrets
+	// returned by GenerateTOTPSecret (160 bits, as advised by RFC 4226).
+	DefaultTOTPSecretSize = 20
+	// DicewareDice is the number of six-sided dice rolled to draw a Diceware word.
+	DicewareDice = 5
+	// DicewareListSize is the number of words of a Diceware word list (6^5).
+	DicewareListSize = 7776
+)
+
+var (
+	// ErrExceedsTotalLength is the error returned when the number of digits and
+	// symbols is greater than the total length.
+	ErrExceedsTotalLength = errors.New("number of digits and symbols must be less than total length")
+	// ErrLettersExceedsAvailable is the error returned when the number of letters
+	// exceeds the number of available letters and repeats are not allowed.
+	ErrLettersExceedsAvailable = errors.New("number of letters exceeds available letters and repeats are not allowed")
+	// ErrDigitsExceedsAvailable is the error returned when the number of digits
+	// exceeds the number of available digits and repeats are not allowed.
+	ErrDigitsExceedsAvailable = errors.New("number of digits exceeds available digits and repeats are not allowed")
+	// ErrSymbolsExceedsAvailable is the error returned when the number of symbols
+	// exceeds the number of available symbols and repeats are not allowed.
+	ErrSymbolsExceedsAvailable = errors.New("number of symbols exceeds available symbols and repeats are not allowed")
+	// ErrLengthExceedsAvailable is the error returned when the length exceeds
+	// the number of available characters and repeats are not allowed.
+	ErrLengthExceedsAvailable = errors.New("length exceeds available characters and repeats are not allowed")
+	// ErrEmptyPool is the error returned when characters of a class are required
+	// but no character of this class is available.
+	ErrEmptyPool = errors.New("no character available for a required class")
+	// ErrCannotSatisfy is the error returned when no password satisfying the
+	// constraints was found in the allowed number of attempts.
+	ErrCannotSatisfy = errors.New("cannot generate a password satisfying the constraints")
+	// ErrTooFewDistinct is the error returned by GenerateN when fewer distinct
+	// passwords than requested were found. It wraps ErrCannotSatisfy.
+	ErrTooFewDistinct = fmt.Errorf("%w: too few distinct passwords", ErrCannotSatisfy)
+	// ErrInsufficientEntropy is the error returned when the estimated entropy
+	// of the requested password is below the minimum of the generator.
+	ErrInsufficientEntropy = errors.New("estimated entropy is below the minimum entropy")
+	// ErrUnknownProfile is the error returned when a compliance profile does
+	// not exist.
+	ErrUnknownProfile = errors.New("unknown compliance profile")
+	// ErrEntropyUnreachable is the error returned when no password made of
+	// the given alphabet reaches the requested entropy.
+	ErrEntropyUnreachable = errors.New("entropy cannot be reached with the alphabet")
+	// ErrNonASCIIPool is the error returned when a character set contains
+	// non-ASCII characters and the generator rejects them.
+	ErrNonASCIIPool = errors.New("character set contains non-ASCII characters")
+	// ErrInvalidCharsetFile is the error returned when a charset file is not
+	// made of key=value lines.
+	ErrInvalidCharsetFile = errors.New("invalid charset file")
+	// ErrInvalidArgument is the error returned when a parameter is out of its
+	// valid range.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrCodeSpaceTooSmall is the error returned when the requested number of
+	// distinct codes exceeds the number of possible codes.
+	ErrCodeSpaceTooSmall = errors.New("number of distinct codes exceeds the number of possible codes")
+	// ErrTimeout is the error returned when no acceptable password was
+	// generated before the deadline.
+	ErrTimeout = errors.New("no acceptable password generated before the deadline")
+	// ErrIncompleteWordlist is the error returned when a Diceware word list
+	// does not have a word for each of the DicewareListSize rolls.
+	ErrIncompleteWordlist = errors.New("incomplete Diceware word list")
+	// ErrInvalidWordlist is the error returned when a Diceware word list file
+	// contains a malformed line.
+	ErrInvalidWordlist = errors.New("invalid Diceware word list")
+	// ErrEdgeClassNotAllowed is the error returned when none of the classes
+	// of characters of the password may start or end it.
+	ErrEdgeClassNotAllowed = errors.New("no class of characters of the password is allowed at its start or end")
+	// ErrInvalidCharset is the error returned by NewGeneratorValidated when a
+	// character set contains a character several times or only spaces.
+	ErrInvalidCharset = errors.New("invalid character set")
+	// ErrBrokenRandomSource is the error returned by SelfTest when the random
+	// source returns constant or repeated data.
+	ErrBrokenRandomSource = errors.New("random source returns predictable data")
+)
+
+// consonants and vowels are the letters of the pronounceable syllables.
+const (
+	consonants = "bcdfghjklmnprstvwxz"
+	vowels     = "aeiou"
+)
+
+// complianceProfiles are the profiles of GenerateCompliant by name.
+var complianceProfiles = map[string]struct {
+	minLength int
+	note      string
+	generate  func(g *Generator, length int) (string, error)
+}{
+	"nist-800-63b": {
+		minLength: 8,
+		note:      "NIST SP 800-63B: at least 8 characters, no composition rule; characters drawn uniformly from lowercase, uppercase, digits and symbols",
+		generate: func(g *Generator, length int) (string, error) {
+			pool := g.lowerLetters + g.upperLetters + g.digits + g.symbols
+			if err := g.verifyEntropy(drawEntropy(distinctCount(pool), length, true)); err != nil {
+				return "", err
+			}
+			return g.randomStringFrom(pool, length, true)
+		},
+	},
+	"pci-dss-4.0": {
+		minLength: 12,
+		note:      "PCI DSS 4.0 (requirement 8.3.6): at least 12 characters, both alphabetic and numeric characters; 2 digits, 1 symbol and letters",
+		generate: func(g *Generator, length int) (string, error) {
+			return g.Generate(length, 2, 1, true, true)
+		},
+	},
+}
+
+// defaultSeparators are the separators of GenerateChunkedMemorable.
+var defaultSeparators = []string{"-", ".", "_"}
+
+// radixDigits are the digits of the bases up to 36.
+const radixDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// digitWords are the English words of the digits.
+var digitWords = [10]string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+
+// embeddedWords is the content of words.txt, one common English word per line.
+//
+//go:embed words.txt
+var embeddedWords string
+
+// defaultWords are the words of the passphrases when GeneratorInput.Words is empty.
+var defaultWords = strings.Fields(embeddedWords)
+
+// embeddedDiceware is the content of diceware.txt, a Diceware word list of
+// DicewareListSize common English words, one roll and its word per line.
+//
+//go:embed diceware.txt
+var embeddedDiceware string
+
+// defaultDiceware is the Diceware word list used when GenerateDiceware is
+// given none.
+var defaultDiceware = mustParseDicewareWordlist(embeddedDiceware)
+
+// qwertyRows are the rows of a QWERTY keyboard, without and with shift.
+var qwertyRows = [2][4]string{
+	{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"},
+	{"~!@#$%^&*()_+", "QWERTYUIOP{}|", "ASDFGHJKL:\"", "ZXCVBNM<>?"},
+}
+
+// qwertyKeys are the row and column of the keys of a QWERTY keyboard by character.
+var qwertyKeys = func() map[rune][2]int {
+	keys := make(map[rune][2]int)
+	for _, rows := range qwertyRows {
+		for row, chars := range rows {
+			for col, r := range []rune(chars) {
+				keys[r] = [2]int{row, col}
+			}
+		}
+	}
+	return keys
+}()
+
+// Generator is the stateful generator which can be used to customize the list
+// of letters, digits, and/or symbols.
+type Generator struct {
+	lowerLetters string
+	upperLetters string
+	digits       string
+	symbols      string
+	words        []string
+
+	reader           io.Reader
+	retryObserver    func(attempt int, reason string)
+	strictSymbols    bool
+	minEntropy       float64
+	entropyModel     func(password string, g *Generator) float64
+	maxAttempts      int
+	maxRunLength     int
+	rejectNonASCII   bool
+	forceLowercase   bool
+	symbolBias       float64
+	excludedRanges   [][2]rune
+	firstClasses     string
+	lastClasses      string
+	normalizer       Normalizer
+	scratch          []rune
+	checks           []check
+	placement        *mathrand.Rand
+	passphraseDigit  bool
+	requireEachClass bool
+	ctx              context.Context
+}
+
+// constructAfter is the number of rejected candidates after which the password
+// is built directly instead of drawn again.
+const constructAfter = 100
+
+// sequenceLength is the number of characters from which a sequence is
+// rejected with GeneratorInput.RejectSequences.
+const sequenceLength = 3
+
+// selfTestSize is the number of bytes of the blocks read by SelfTest.
+const selfTestSize = 32
+
+// batchReadSize is the number of random bytes read at once by the batch copies
+// of the generator.
+const batchReadSize = 4096
+
+// check inspects a password and returns the reason why it is rejected, or an
+// empty string if it is accepted. A check rejecting a password must also
+// reject every password starting with it, so that it can be applied to the
+// prefixes of a password while it is built.
+type check func(password string) string
+
+// charClass is a class of characters of a password.
+type charClass struct {
+	name   string
+	pool   string
+	n      int
+	unique bool
+	bias   float64
+}
+
+// Limiter is the rate limiter waited on by GenerateThrottled. The *rate.Limiter
+// type of golang.org/x/time/rate satisfies it.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// Normalizer is the Unicode normalization applied by WithNormalization. The
+// norm.Form type of golang.org/x/text/unicode/norm (e.g. norm.NFC) satisfies it.
+type Normalizer interface {
+	String(s string) string
+}
+
+// Option is a function which customizes a Generator when it is created.
+type Option func(*Generator)
+
+// GeneratorInput is used as input to the NewGenerator function.
+type GeneratorInput struct {
+	LowerLetters string
+	UpperLetters string
+	Digits       string
+	Symbols      string
+	// ExcludeAmbiguous removes the AmbiguousChars from the letters and digits.
+	ExcludeAmbiguous bool
+	// Reader is the source of random bytes (crypto/rand if nil).
+	Reader io.Reader
+	// Words are the words of the passphrases (an embedded English list if empty).
+	Words []string
+	// Blocklist are the substrings the passwords must not contain, e.g. the
+	// company name (the case is ignored).
+	Blocklist []string
+	// RejectSequences rejects the passwords containing 3 or more sequential
+	// (e.g. abcd or 321) or identical characters.
+	RejectSequences bool
+}
+
+// GenerateConfig gathers the parameters used to generate a password.
+type GenerateConfig struct {
+	// Length is the total number of characters.
+	Length int
+	// NumDigits is the number of digits to include.
+	NumDigits int
+	// NumSymbols is the number of symbols to include.
+	NumSymbols int
+	// AllowUppercase includes the uppercase letters in the letters.
+	AllowUppercase bool
+	// AllowRepeat allows repeat characters.
+	AllowRepeat bool
+	// UppercaseRatio is the approximate proportion of uppercase letters among
+	// the letters, between 0 and 1. The default 0 draws the letters from the
+	// lowercase and uppercase letters together.
+	UppercaseRatio float64
+	// NoLeadingSymbol makes the first character a letter (neither a symbol
+	// nor a digit), for the systems trimming or rejecting such characters.
+	NoLeadingSymbol bool
+	// NoTrailingSymbol makes the last character a letter (neither a symbol
+	// nor a digit).
+	NoTrailingSymbol bool
+}
+
+// GenerateParams is the former name of GenerateConfig.
+type GenerateParams = GenerateConfig
+
+// PasswordResult describes a generated password, e.g. to print it as JSON.
+type PasswordResult struct {
+	// Password is the generated password.
+	Password string `json:"password"`
+	// Length is the number of characters of the password.
+	Length int `json:"length"`
+	// Digits is the number of digits of the password.
+	Digits int `json:"digits"`
+	// Symbols is the number of symbols of the password.
+	Symbols int `json:"symbols"`
+	// EntropyBits is the estimated entropy of the password in bits.
+	EntropyBits float64 `json:"entropy_bits"`
+}
+
+/*
+Function which creates a new generator from a specified configuration.
+	The duplicate characters of the sets are removed, so that every character
+	of a set is drawn with the same probability (see NewGeneratorValidated to
+	reject them instead).
+
+	Parameters:
+	-----------
+		i (*GeneratorInput): specified configuration
+			Note: if i == nil, we use default values
+		opts (...Option): options applied to the generator after the configuration
+
+	Returns:
+	--------
+		*Generator - a generator pointor
+*/
+func NewGenerator(i *GeneratorInput, opts ...Option) *Generator {
+	// Put the default values
+	if i == nil {
+		i = new(GeneratorInput)
+	}
+
+	// Create the Generator (we save here the pointer to access easily attributes of the object)
+	g := &Generator{
+		lowerLetters: i.LowerLetters,
+		upperLetters: i.UpperLetters,
+		digits:       i.Digits,
+		symbols:      i.Symbols,
+		reader:       rand.Reader,
+		maxAttempts:  DefaultMaxAttempts,
+	}
+
+	// If the value is "", we put the default associated value
+	if i.Reader != nil {
+		g.reader = i.Reader
+	}
+	if g.lowerLetters == "" {
+		g.lowerLetters = LowerLetters
+	}
+	if g.upperLetters == "" {
+		g.upperLetters = UpperLetters
+	}
+	if g.digits == "" {
+		g.digits = Digits
+	}
+	if g.symbols == "" {
+		g.symbols = Symbols
+	}
+	g.words = distinctWords(i.Words)
+	if len(g.words) == 0 {
+		g.words = defaultWords
+	}
+
+	// Add the checks of the configuration
+	if len(i.Blocklist) > 0 {
+		g.checks = append(g.checks, avoidSubstrings(i.Blocklist, 1))
+	}
+	if i.RejectSequences {
+		g.checks = append(g.checks, avoidSequences(sequenceLength))
+	}
+
+	// Apply the options
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	// Fold the characters to lowercase, which may merge some of them
+	if g.forceLowercase {
+		g.lowerLetters = strings.ToLower(g.lowerLetters)
+		g.upperLetters = strings.ToLower(g.upperLetters)
+		g.digits = strings.ToLower(g.digits)
+		g.symbols = strings.ToLower(g.symbols)
+		words := make([]string, len(g.words))
+		for i, word := range g.words {
+			words[i] = strings.ToLower(word)
+		}
+		g.words = distinctWords(words)
+	}
+
+	// Remove the ambiguous characters
+	if i.ExcludeAmbiguous {
+		isClear := func(r rune) bool {
+			return !strings.ContainsRune(AmbiguousChars, r)
+		}
+		g.lowerLetters = filterPool(g.lowerLetters, isClear)
+		g.upperLetters = filterPool(g.upperLetters, isClear)
+		g.digits = filterPool(g.digits, isClear)
+	}
+
+	// Remove the excluded characters
+	if len(g.excludedRanges) > 0 {
+		keep := func(r rune) bool {
+			for _, rg := range g.excludedRanges {
+				if r >= rg[0] && r <= rg[1] {
+					return false
+				}
+			}
+			return true
+		}
+		g.lowerLetters = filterPool(g.lowerLetters, keep)
+		g.upperLetters = filterPool(g.upperLetters, keep)
+		g.digits = filterPool(g.digits, keep)
+		g.symbols = filterPool(g.symbols, keep)
+	}
+
+	// Normalize the characters, merging the equivalent ones
+	if g.normalizer != nil {
+		normalize := func(pool string) string {
+			var b strings.Builder
+			for _, r := range pool {
+				n := g.normalizer.String(string(r))
+				if utf8.RuneCountInString(n) != 1 {
+					// Keep the character whole, it is normalized in the passwords
+					n = string(r)
+				}
+				b.WriteString(n)
+			}
+			return string(distinctChars(b.String()))
+		}
+		g.lowerLetters = normalize(g.lowerLetters)
+		g.upperLetters = normalize(g.upperLetters)
+		g.digits = normalize(g.digits)
+		g.symbols = normalize(g.symbols)
+	}
+
+	// Remove the duplicate characters, which would be drawn more often
+	g.lowerLetters = string(distinctChars(g.lowerLetters))
+	g.upperLetters = string(distinctChars(g.upperLetters))
+	g.digits = string(distinctChars(g.digits))
+	g.symbols = string(distinctChars(g.symbols))
+
+	return g
+}
+
+/*
+Function which creates a generator drawing from a deterministic source seeded with the given seed.
+	Two generators created with the same seed, configuration and options
+	generate the same passwords in the same order, e.g. for test fixtures or
+	demos. The source is a ChaCha8 stream keyed by the seed only, so anyone
+	knowing the seed knows the passwords: it is NOT secure and must only be
+	used for tests.
+
+	Parameters:
+	-----------
+		input (*GeneratorInput): specified configuration (its Reader is ignored)
+			Note: if input == nil, we use default values
+		seed (int64): seed of the source
+		opts (...Option): options applied to the generator after the configuration
+
+	Returns:
+	--------
+		*Generator - a generator pointor
+*/
+func NewSeededGenerator(input *GeneratorInput, seed int64, opts ...Option) *Generator {
+	var i GeneratorInput
+	if input != nil {
+		i = *input
+	}
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], uint64(seed))
+	i.Reader = mathrand.NewChaCha8(key)
+	return NewGenerator(&i, opts...)
+}
+
+/*
+Function which creates a new generator from a specified configuration, rejecting the invalid character sets.
+	NewGenerator silently removes the duplicate characters of the sets, while
+	this function returns ErrInvalidCharset if a set contains a character
+	several times or is only made of spaces, and ErrEmptyPool if a set is
+	emptied by the options (e.g. WithExcludeRange). The configuration of the
+	generator is then verified by Validate.
+
+	Parameters:
+	-----------
+		i (*GeneratorInput): specified configuration
+			Note: if i == nil, we use default values
+		opts (...Option): options applied to the generator after the configuration
+
+	Returns:
+	--------
+		*Generator, error - a generator pointor and the error if the configuration is invalid
+*/
+func NewGeneratorValidated(i *GeneratorInput, opts ...Option) (*Generator, error) {
+	if i != nil {
+		for _, set := range []struct{ name, chars string }{
+			{"lowercase letters", i.LowerLetters},
+			{"uppercase letters", i.UpperLetters},
+			{"digits", i.Digits},
+			{"symbols", i.Symbols},
+		} {
+			if set.chars != "" && strings.TrimSpace(set.chars) == "" {
+				return nil, fmt.Errorf("%w: the %s are only spaces", ErrInvalidCharset, set.name)
+			}
+			seen := make(map[rune]bool)
+			for _, r := range set.chars {
+				if seen[r] {
+					return nil, fmt.Errorf("%w: the %s contain %q several times", ErrInvalidCharset, set.name, r)
+				}
+				seen[r] = true
+			}
+		}
+	}
+
+	g := NewGenerator(i, opts...)
+	for _, set := range []struct{ name, chars string }{
+		{"lowercase letters", g.lowerLetters},
+		{"uppercase letters", g.upperLetters},
+		{"digits", g.digits},
+		{"symbols", g.symbols},
+	} {
+		if set.chars == "" {
+			return nil, fmt.Errorf("%w: no %s left", ErrEmptyPool, set.name)
+		}
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+/*
+Function which creates a new generator from options only.
+	Same as NewGenerator(nil, opts...): the character sets not changed by an
+	option (e.g. WithDigits) keep their default value.
+
+	Parameters:
+	-----------
+		opts (...Option): options applied to the generator
+
+	Returns:
+	--------
+		*Generator - a generator pointor
+*/
+func NewGeneratorWithOptions(opts ...Option) *Generator {
+	return NewGenerator(nil, opts...)
+}
+
+/*
+Function which creates a new generator from the character sets defined in a file.
+	The file is made of "key=value" lines where the key is lower, upper,
+	digits or symbols and the value is the list of characters (the spaces
+	around the key and the value are ignored, so a set cannot contain a
+	space). Empty lines and lines starting with # are ignored, and the sets
+	not defined in the file keep their default value.
+
+	Parameters:
+	-----------
+		path (string): path of the charset file
+		opts (...Option): options applied to the generator after the configuration
+
+	Returns:
+	--------
+		*Generator, error - a generator pointor and the error if the file was not read
+*/
+func NewGeneratorFromCharsetFile(path string, opts ...Option) (*Generator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	input, err := parseCharset(f, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(input, opts...), nil
+}
+
+/*
+Function which parses the content of a charset file.
+	Parameters:
+	-----------
+		r (io.Reader): content of the file
+		name (string): name of the file used in the error messages
+
+	Returns:
+	--------
+		*GeneratorInput, error - configuration and the error if the content is invalid
+*/
+func parseCharset(r io.Reader, name string) (*GeneratorInput, error) {
+	input := new(GeneratorInput)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// Assign the value to its set
+		key, value, found := strings.Cut(text, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: %s:%d: expected key=value, got %q", ErrInvalidCharsetFile, name, line, text)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "lower":
+			input.LowerLetters = value
+		case "upper":
+			input.UpperLetters = value
+		case "digits":
+			input.Digits = value
+		case "symbols":
+			input.Symbols = value
+		default:
+			return nil, fmt.Errorf("%w: %s:%d: unknown key %q (expected lower, upper, digits or symbols)", ErrInvalidCharsetFile, name, line, strings.TrimSpace(key))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+/*
+Function which creates an option registering an observer of the rejected candidates.
+	The observer is called each time a candidate character or password is
+	rejected and drawn again, with the number of the attempt and the reason of
+	the rejection. It does not change the generated passwords.
+
+	Parameters:
+	-----------
+		observer (func(int, string)): function called on each rejection
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithRetryObserver(observer func(attempt int, reason string)) Option {
+	return func(g *Generator) {
+		g.retryObserver = observer
+	}
+}
+
+/*
+Function which creates an option forbidding repeated symbols even when repeats are allowed.
+	Letters and digits follow the allowRepeat parameter but a symbol never
+	appears twice in a password, so ErrSymbolsExceedsAvailable is returned as
+	soon as more symbols than available are requested.
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithStrictSymbolUniqueness() Option {
+	return func(g *Generator) {
+		g.strictSymbols = true
+	}
+}
+
+/*
+Function which creates an option setting the minimal entropy of the generated passwords.
+	The entropy of the requested password is estimated before generating it
+	and ErrInsufficientEntropy is returned if it is below the given number of
+	bits. With WithEntropyModel, each generated password is measured instead
+	and drawn again while it is below the minimum. The default value 0
+	accepts any password.
+	The minimum applies to the passwords made of the character sets: Generate
+	and its variants, GenerateWithMinimums, GenerateFromPattern, GenerateSplit,
+	GenerateCompliant and GeneratePIN (the last four always estimate the
+	entropy from the sizes of the sets). The codes, identifiers and
+	passphrases are not concerned.
+
+	Parameters:
+	-----------
+		bits (float64): minimal entropy in bits
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithMinEntropy(bits float64) Option {
+	return func(g *Generator) {
+		g.minEntropy = bits
+	}
+}
+
+/*
+Function which creates an option replacing the estimation of the entropy of the passwords.
+	The model receives each generated password and the generator, and returns
+	its entropy in bits. It is used by GenerateWithEntropy and by the minimal
+	entropy of WithMinEntropy, a password below the minimum being drawn again.
+	By default (or with a nil model) the entropy is computed from the sizes of
+	the character sets, as Entropy does.
+
+	Parameters:
+	-----------
+		model (func(string, *Generator) float64): function estimating the entropy of a password in bits
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithEntropyModel(model func(password string, g *Generator) float64) Option {
+	return func(g *Generator) {
+		g.entropyModel = model
+	}
+}
+
+/*
+Function which creates an option appending a digit to the passphrases.
+	The digit is drawn among the digits of the generator and appended to the
+	last word of the passphrases of GeneratePassphrase.
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithPassphraseDigit() Option {
+	return func(g *Generator) {
+		g.passphraseDigit = true
+	}
+}
+
+/*
+Function which creates an option limiting the number of consecutive identical characters.
+	A character which would make a run longer than n is drawn again while the
+	password is assembled. ErrCannotSatisfy is returned if the characters are
+	too few to respect the limit. A limit of 0 (the default) or not lower than
+	the password length changes nothing.
+
+	Parameters:
+	-----------
+		n (int): maximal length of a run of the same character
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithMaxRunLength(n int) Option {
+	return func(g *Generator) {
+		g.maxRunLength = n
+	}
+}
+
+/*
+Function which creates an option changing the number of candidates drawn by the methods which regenerate a password until it satisfies a constraint.
+	A value not positive keeps DefaultMaxAttempts.
+
+	Parameters:
+	-----------
+		n (int): maximal number of attempts
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithMaxAttempts(n int) Option {
+	return func(g *Generator) {
+		if n > 0 {
+			g.maxAttempts = n
+		}
+	}
+}
+
+/*
+Function which creates an option replacing the symbols by URLSafeSymbols.
+	Note that only 4 symbols are left, so at most 4 symbols can be requested
+	when repeats are not allowed.
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithURLSafeSymbols() Option {
+	return func(g *Generator) {
+		g.symbols = URLSafeSymbols
+	}
+}
+
+/*
+Function which creates an option rejecting the character sets containing non-ASCII characters.
+	Many systems silently mangle non-ASCII characters, so with this option
+	Validate and the generation methods return ErrNonASCIIPool when a set of
+	the generator contains one.
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithRejectNonASCII() Option {
+	return func(g *Generator) {
+		g.rejectNonASCII = true
+	}
+}
+
+/*
+Function which creates an option guaranteeing a character of each enabled class in the passwords.
+	The passwords then contain at least a lowercase letter, an uppercase
+	letter if uppercase is allowed, and the requested digits and symbols.
+	Without this option, the letters are drawn from the lowercase and
+	uppercase letters together, so a password may lack one of the cases.
+	ErrInvalidArgument is returned if the letters are too few.
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithRequireEachEnabledClass() Option {
+	return func(g *Generator) {
+		g.requireEachClass = true
+	}
+}
+
+/*
+Function which creates an option generating lowercase passwords even if uppercase is allowed.
+	The character sets and the words are folded to lowercase when the
+	generator is created, so the passwords of every method match what a
+	system lowercasing them stores (e.g. the U class of GenerateFromPattern
+	draws lowercase letters), and GenerateHybrid and GeneratePassphrase do not
+	capitalize. Note that the
+	uppercase letters then bring no entropy: a letter is drawn among the
+	lowercase letters only, which Entropy takes into account.
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithForceLowercase() Option {
+	return func(g *Generator) {
+		g.forceLowercase = true
+	}
+}
+
+/*
+Function which creates an option placing the symbols toward the middle of the password.
+	The position of a symbol is the average of 1+bias uniformly drawn
+	positions (the fractional part of bias giving the probability of one more
+	draw), so a bias of 0 (the default) keeps the uniform placement, a bias
+	of 1 gives a triangular distribution and greater biases pack the symbols
+	closer to the middle. The other characters stay uniformly placed between
+	the symbols (so they are pushed toward the edges by them).
+
+	Parameters:
+	-----------
+		bias (float64): strength of the bias (not negative)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithSymbolPlacementBias(bias float64) Option {
+	return func(g *Generator) {
+		g.symbolBias = bias
+	}
+}
+
+/*
+Function which creates an option rejecting the passwords containing a walk on a QWERTY keyboard.
+	A walk is a sequence of characters whose keys are next to each other,
+	horizontally, vertically or diagonally (e.g. qwe, qaz or zaq), shift
+	being ignored. The same key typed twice does not continue a walk.
+
+	Parameters:
+	-----------
+		maxRun (int): maximal number of characters of a walk (at least 1)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithNoKeyboardWalk(maxRun int) Option {
+	return func(g *Generator) {
+		g.checks = append(g.checks, avoidKeyboardWalks(maxRun))
+	}
+}
+
+/*
+Function which creates an option rejecting the passwords containing an anagram of a banned word.
+	A password is rejected if the letters of a word (ignoring the case) are all
+	in the password, in any order and at any position, so it is stricter than
+	searching the word. It is meant for short words, longer passwords being
+	more likely to contain all their letters.
+
+	Parameters:
+	-----------
+		words ([]string): banned words
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithAvoidAnagrams(words []string) Option {
+	return func(g *Generator) {
+		g.checks = append(g.checks, avoidAnagrams(words))
+	}
+}
+
+/*
+Function which creates an option rejecting the passwords containing a banned substring.
+	Same as GeneratorInput.Blocklist, e.g. to ban substrings with a generator
+	created by NewGeneratorFromCharsetFile. The case is ignored and every
+	substring is banned, whatever its length.
+
+	Parameters:
+	-----------
+		substrings ([]string): banned substrings
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithBlocklist(substrings []string) Option {
+	return func(g *Generator) {
+		if len(substrings) > 0 {
+			g.checks = append(g.checks, avoidSubstrings(substrings, 1))
+		}
+	}
+}
+
+/*
+Function which creates an option drawing the positions of the characters from a seeded source.
+	Only meant for tests asserting the structure of the passwords: the
+	characters are still drawn from the random source of the generator, but
+	the same seed gives the same placements. The generator must then not be
+	used by several goroutines at the same time, except by
+	GenerateManyParallel which locks the placements.
+
+	Parameters:
+	-----------
+		seed (int64): seed of the placements
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithPlacementSeed(seed int64) Option {
+	return func(g *Generator) {
+		g.placement = mathrand.New(mathrand.NewPCG(uint64(seed), 0))
+	}
+}
+
+/*
+Function which creates an option replacing the lowercase letters of the generator.
+	Parameters:
+	-----------
+		lowerLetters (string): lowercase letters (the default LowerLetters if empty)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithLowerLetters(lowerLetters string) Option {
+	return func(g *Generator) {
+		if lowerLetters != "" {
+			g.lowerLetters = lowerLetters
+		}
+	}
+}
+
+/*
+Function which creates an option replacing the uppercase letters of the generator.
+	Parameters:
+	-----------
+		upperLetters (string): uppercase letters (the default UpperLetters if empty)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithUpperLetters(upperLetters string) Option {
+	return func(g *Generator) {
+		if upperLetters != "" {
+			g.upperLetters = upperLetters
+		}
+	}
+}
+
+/*
+Function which creates an option replacing the digits of the generator.
+	Parameters:
+	-----------
+		digits (string): digits (the default Digits if empty)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithDigits(digits string) Option {
+	return func(g *Generator) {
+		if digits != "" {
+			g.digits = digits
+		}
+	}
+}
+
+/*
+Function which creates an option replacing the symbols of the generator.
+	Parameters:
+	-----------
+		symbols (string): symbols (the default Symbols if empty)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithSymbols(symbols string) Option {
+	return func(g *Generator) {
+		if symbols != "" {
+			g.symbols = symbols
+		}
+	}
+}
+
+/*
+Function which creates an option replacing crypto/rand by another source of random bytes.
+	The source may return short reads, the missing bytes are read again.
+
+	Parameters:
+	-----------
+		r (io.Reader): source of random bytes, e.g. a hardware RNG device (crypto/rand if nil)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithReader(r io.Reader) Option {
+	return func(g *Generator) {
+		g.SetReader(r)
+	}
+}
+
+/*
+Function which creates an option removing a range of characters from the character sets.
+	The characters are removed once all the options are applied, so the range
+	also applies to the sets given by other options. The option can be given
+	several times to remove several ranges.
+
+	Parameters:
+	-----------
+		lo (rune): first character of the range
+		hi (rune): last character of the range (included)
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithExcludeRange(lo, hi rune) Option {
+	return func(g *Generator) {
+		g.excludedRanges = append(g.excludedRanges, [2]rune{lo, hi})
+	}
+}
+
+/*
+Function which creates an option restricting the classes of the first character.
+	The classes are given by their letter: L (lowercase letters), U (uppercase
+	letters), D (digits) and S (symbols), e.g. "LU" for fields which must
+	start with a letter. An empty string allows all the classes.
+
+	Parameters:
+	-----------
+		classes (string): allowed classes among L, U, D and S
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithAllowedFirstClasses(classes string) Option {
+	return func(g *Generator) {
+		g.firstClasses = classes
+	}
+}
+
+/*
+Function which creates an option restricting the classes of the last character.
+	The classes are given as for WithAllowedFirstClasses.
+
+	Parameters:
+	-----------
+		classes (string): allowed classes among L, U, D and S
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithAllowedLastClasses(classes string) Option {
+	return func(g *Generator) {
+		g.lastClasses = classes
+	}
+}
+
+/*
+Function which creates an option normalizing the passwords to a Unicode normalization form.
+	The characters of the sets are normalized one by one when the generator is
+	created, equivalent characters being merged, so the repeat rules apply to
+	the normalized characters. A character whose normalized form has several
+	runes (e.g. with NFD) stays one character of its set and is decomposed in
+	the passwords. Without this option, the passwords are left as drawn.
+
+	Parameters:
+	-----------
+		form (Normalizer): normalization form, e.g. norm.NFC
+
+	Returns:
+	--------
+		Option - option to give to NewGenerator
+*/
+func WithNormalization(form Normalizer) Option {
+	return func(g *Generator) {
+		g.normalizer = form
+	}
+}
+
+/*
+Function which replaces the source of random bytes of the generator.
+	Method of Generator type
+	A deterministic source (e.g. a seeded math/rand/v2 ChaCha8) gives
+	reproducible passwords, which is only meant for tests. It must not be
+	called while the generator is used by other goroutines.
+
+	Parameters:
+	-----------
+		r (io.Reader): source of random bytes (crypto/rand if nil)
+*/
+func (g *Generator) SetReader(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+	g.reader = r
+}
+
+/*
+Function which verifies the configuration of the generator.
+	Method of Generator type
+
+	Returns:
+	--------
+		error - ErrNonASCIIPool if non-ASCII characters are rejected and a set contains one,
+			ErrInvalidArgument if an allowed edge class is unknown, nil otherwise
+*/
+func (g *Generator) Validate() error {
+	for _, classes := range []string{g.firstClasses, g.lastClasses} {
+		if i := strings.IndexFunc(classes, func(r rune) bool { return !strings.ContainsRune("LUDS", r) }); i >= 0 {
+			return fmt.Errorf("%w: unknown class %q (expected L, U, D or S)", ErrInvalidArgument, classes[i])
+		}
+	}
+	if g.rejectNonASCII {
+		for _, pool := range []string{g.lowerLetters, g.upperLetters, g.digits, g.symbols} {
+			for _, r := range pool {
+				if r >= utf8.RuneSelf {
+					return fmt.Errorf("%w: %q", ErrNonASCIIPool, r)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Function which verifies that the random source of the generator works.
+	Method of Generator type
+	Two blocks of selfTestSize bytes are read: the test fails if a read
+	fails, if a block is made of a single repeated byte or if both blocks are
+	equal. It only detects broken sources, e.g. a failing or blocked device,
+	and is no statistical test of the quality of the source.
+
+	Returns:
+	--------
+		error - error of the source or ErrBrokenRandomSource, nil if the source works
+*/
+func (g *Generator) SelfTest() error {
+	var blocks [2][selfTestSize]byte
+	for i := range blocks {
+		if _, err := io.ReadFull(g.reader, blocks[i][:]); err != nil {
+			return fmt.Errorf("reading the random source: %w", err)
+		}
+		if bytes.Count(blocks[i][:], blocks[i][:1]) == selfTestSize {
+			return fmt.Errorf("%w: %d times the byte %#x", ErrBrokenRandomSource, selfTestSize, blocks[i][0])
+		}
+	}
+	if blocks[0] == blocks[1] {
+		return fmt.Errorf("%w: the same %d bytes twice", ErrBrokenRandomSource, selfTestSize)
+	}
+	return nil
+}
+
+/*
+Function which tells if a character is one of the digits of the generator.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		r (rune): character to test
+
+	Returns:
+	--------
+		bool - true if the character is a digit
+*/
+func (g *Generator) isDigit(r rune) bool {
+	return strings.ContainsRune(g.digits, r)
+}
+
+/*
+Function which notifies the retry observer (if any) of a rejection.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		attempt (int): number of the rejected attempt
+		reason (string): reason of the rejection
+*/
+func (g *Generator) notifyRetry(attempt int, reason string) {
+	if g.retryObserver != nil {
+		g.retryObserver(attempt, reason)
+	}
+}
+
+/*
+Function to generate a password with the required arguments.
+	Method of Generator type
+	The digits and symbols are placed uniformly: every arrangement of the
+	letters, digits and symbols is equally likely (see WithSymbolPlacementBias
+	to change this for the symbols).
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: a *GenerateError with the offending values is returned if the counts cannot be satisfied
+*/
+func (g *Generator) Generate(length, numDigits, numSymbols int, allowUpper, allowRepeat bool) (string, error) {
+	return g.GenerateWithConfig(GenerateConfig{
+		Length:         length,
+		NumDigits:      numDigits,
+		NumSymbols:     numSymbols,
+		AllowUppercase: allowUpper,
+		AllowRepeat:    allowRepeat,
+	})
+}
+
+/*
+Function to generate a password with the required arguments, panicking on error.
+	Method of Generator type
+	Like regexp.MustCompile, it is meant for package initializers with static
+	arguments known to be valid. Use Generate when the arguments come from
+	the user or when the random source may fail.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string - password
+			Note: panics if the password was not generated
+*/
+func (g *Generator) MustGenerate(length, numDigits, numSymbols int, allowUpper, allowRepeat bool) string {
+	pwd, err := g.Generate(length, numDigits, numSymbols, allowUpper, allowRepeat)
+	if err != nil {
+		panic("passwordgenerator: Generate: " + err.Error())
+	}
+	return pwd
+}
+
+/*
+Function to generate a password with the parameters given by name.
+	Method of Generator type
+	Same as Generate, without the risk of swapping the positional arguments.
+
+	Parameters:
+	-----------
+		cfg (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateWithConfig(cfg GenerateConfig) (string, error) {
+	return g.GenerateContext(context.Background(), cfg)
+}
+
+/*
+Function to generate a password which can be cancelled by a context.
+	Method of Generator type
+	The context is checked between the attempts, so the long retry loops of
+	the constrained configurations (e.g. without repeats) stop promptly.
+
+	Parameters:
+	-----------
+		ctx (context.Context): context cancelling the generation
+		cfg (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated (the context error if it was cancelled)
+*/
+func (g *Generator) GenerateContext(ctx context.Context, cfg GenerateConfig) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return g.withContext(ctx).generate(cfg)
+}
+
+/*
+Function to generate a password and to tell which source of random bytes was used.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, string, error - password, source ("crypto/rand" or the type of the reader given to WithReader) and the error if the password was not generated
+*/
+func (g *Generator) GenerateWithSourceInfo(params GenerateConfig) (password string, source string, err error) {
+	source = "crypto/rand"
+	if g.reader != rand.Reader {
+		source = fmt.Sprintf("%T", g.reader)
+	}
+	password, err = g.generate(params)
+	if err != nil {
+		return "", "", err
+	}
+	return password, source, nil
+}
+
+/*
+Function to generate a password and to measure its entropy in the same call.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, float64, error - password, its entropy in bits and the error if the password was not generated
+*/
+func (g *Generator) GenerateWithEntropy(params GenerateConfig) (password string, bits float64, err error) {
+	password, err = g.generate(params)
+	if err != nil {
+		return "", 0, err
+	}
+	return password, g.passwordEntropy(password, params), nil
+}
+
+/*
+Function to generate a password and to describe it in a PasswordResult.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		PasswordResult, error - description of the password and the error if the password was not generated
+*/
+func (g *Generator) GenerateResult(params GenerateConfig) (PasswordResult, error) {
+	password, err := g.generate(params)
+	if err != nil {
+		return PasswordResult{}, err
+	}
+	return g.Result(password, params), nil
+}
+
+/*
+Function which describes a password generated with the given parameters.
+	Method of Generator type
+	The digits and symbols are counted using the character sets of the
+	generator.
+
+	Parameters:
+	-----------
+		password (string): generated password
+		params (GenerateConfig): parameters the password was generated with
+
+	Returns:
+	--------
+		PasswordResult - description of the password
+*/
+func (g *Generator) Result(password string, params GenerateConfig) PasswordResult {
+	result := PasswordResult{
+		Password:    password,
+		Length:      utf8.RuneCountInString(password),
+		EntropyBits: g.passwordEntropy(password, params),
+	}
+	for _, r := range password {
+		if strings.ContainsRune(g.digits, r) {
+			result.Digits++
+		} else if strings.ContainsRune(g.symbols, r) {
+			result.Symbols++
+		}
+	}
+	return result
+}
+
+/*
+Function which estimates the entropy of a generated password.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		password (string): generated password
+		params (GenerateConfig): parameters the password was generated with
+
+	Returns:
+	--------
+		float64 - entropy in bits given by the model of WithEntropyModel, or by Entropy if there is none
+*/
+func (g *Generator) passwordEntropy(password string, params GenerateConfig) float64 {
+	if g.entropyModel != nil {
+		return g.entropyModel(password, g)
+	}
+	return g.Entropy(params)
+}
+
+/*
+Function which returns the maximal length of a password without repeat characters.
+	Method of Generator type
+	It is the sum of MaxUniqueLetters, MaxUniqueDigits and MaxUniqueSymbols,
+	e.g. to bound the length proposed by a user interface.
+
+	Parameters:
+	-----------
+		allowUpper (bool): include uppercase
+
+	Returns:
+	--------
+		int - maximal length with repeat characters not allowed
+*/
+func (g *Generator) MaxUniqueLength(allowUpper bool) int {
+	return g.MaxUniqueLetters(allowUpper) + g.MaxUniqueDigits() + g.MaxUniqueSymbols()
+}
+
+/*
+Function which returns the maximal number of letters of a password without repeat characters.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		allowUpper (bool): include uppercase
+
+	Returns:
+	--------
+		int - maximal number of letters, beyond which ErrLettersExceedsAvailable is returned
+*/
+func (g *Generator) MaxUniqueLetters(allowUpper bool) int {
+	letters, _, _ := g.pools(allowUpper)
+	return distinctCount(letters)
+}
+
+/*
+Function which returns the maximal number of digits of a password without repeat characters.
+	Method of Generator type
+
+	Returns:
+	--------
+		int - maximal number of digits, beyond which ErrDigitsExceedsAvailable is returned
+*/
+func (g *Generator) MaxUniqueDigits() int {
+	_, digits, _ := g.pools(false)
+	return distinctCount(digits)
+}
+
+/*
+Function which returns the maximal number of symbols of a password without repeat characters.
+	Method of Generator type
+	With WithStrictSymbolUniqueness, it is also the limit when repeat
+	characters are allowed.
+
+	Returns:
+	--------
+		int - maximal number of symbols, beyond which ErrSymbolsExceedsAvailable is returned
+*/
+func (g *Generator) MaxUniqueSymbols() int {
+	_, _, symbols := g.pools(false)
+	return distinctCount(symbols)
+}
+
+/*
+Function which returns all the characters the passwords may be made of.
+	Method of Generator type
+	The letters (lowercase then uppercase if allowed), digits and symbols are
+	concatenated, a character being kept once if several sets contain it.
+
+	Parameters:
+	-----------
+		allowUpper (bool): include uppercase
+
+	Returns:
+	--------
+		string - characters of the passwords
+*/
+func (g *Generator) Pool(allowUpper bool) string {
+	letters, digits, symbols := g.pools(allowUpper)
+	return string(distinctChars(letters + digits + symbols))
+}
+
+/*
+Function which returns the number of characters the passwords may be made of.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		allowUpper (bool): include uppercase
+
+	Returns:
+	--------
+		int - number of characters of Pool (not of bytes)
+*/
+func (g *Generator) PoolSize(allowUpper bool) int {
+	return utf8.RuneCountInString(g.Pool(allowUpper))
+}
+
+/*
+Function which estimates the entropy of the passwords generated with the required arguments.
+	Method of Generator type
+	Positional form of Entropy, taking the same arguments as Generate.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		float64 - entropy in bits (0 if no password can be generated with these arguments)
+*/
+func (g *Generator) EntropyBits(length, numDigits, numSymbols int, allowUpper, allowRepeat bool) float64 {
+	return g.Entropy(GenerateConfig{
+		Length:         length,
+		NumDigits:      numDigits,
+		NumSymbols:     numSymbols,
+		AllowUppercase: allowUpper,
+		AllowRepeat:    allowRepeat,
+	})
+}
+
+/*
+Function which estimates the entropy of the passwords generated with the given parameters.
+	Method of Generator type
+	The entropy is the base 2 logarithm of the number of passwords the generator
+	can produce: the choices of letters, digits and symbols among the configured
+	sets (permutations when repeats are not allowed) times the ways to arrange
+	the three classes in the password.
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		float64 - entropy in bits (0 if no password can be generated with these parameters)
+*/
+func (g *Generator) Entropy(params GenerateConfig) float64 {
+	letterPool, digitPool, symbolPool := g.pools(params.AllowUppercase)
+	letters, digits, symbols := distinctCount(letterPool), distinctCount(digitPool), distinctCount(symbolPool)
+	chars := params.Length - params.NumDigits - params.NumSymbols
+	if chars < 0 || params.NumDigits < 0 || params.NumSymbols < 0 {
+		return 0
+	}
+
+	// Choices inside each class
+	bits := 0.0
+	for _, class := range []struct {
+		poolSize, n int
+		repeat      bool
+	}{
+		{letters, chars, params.AllowRepeat},
+		{digits, params.NumDigits, params.AllowRepeat},
+		{symbols, params.NumSymbols, params.AllowRepeat && !g.strictSymbols},
+	} {
+		if class.n == 0 {
+			continue
+		}
+		if class.repeat {
+			bits += float64(class.n) * math.Log2(float64(class.poolSize))
+			continue
+		}
+		if class.n > class.poolSize {
+			return 0
+		}
+		bits += log2Factorial(class.poolSize) - log2Factorial(class.poolSize-class.n)
+	}
+
+	// Arrangements of the classes in the password
+	bits += log2Factorial(params.Length) - log2Factorial(chars) - log2Factorial(params.NumDigits) - log2Factorial(params.NumSymbols)
+	return bits
+}
+
+/*
+Function which generates a password from the given parameters.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) generate(params GenerateConfig) (string, error) {
+	classes, err := g.charClasses(params)
+	if err != nil {
+		return "", err
+	}
+	return g.generateClasses(params, classes)
+}
+
+/*
+Function which generates a password made of the characters of the given classes.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+		classes ([]charClass): classes of characters of the password, as returned by charClasses
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) generateClasses(params GenerateConfig, classes []charClass) (string, error) {
+	// Verify if the run length limit can be respected
+	if g.maxRunLength > 0 && params.Length > g.maxRunLength {
+		pool := ""
+		for _, class := range classes {
+			if class.n > 0 {
+				pool += class.pool
+			}
+		}
+		if distinctCount(pool) < 2 {
+			return "", ErrCannotSatisfy
+		}
+	}
+
+	// Draw passwords until their edges are allowed and they pass the checks of the options
+	for attempt := 1; ; attempt++ {
+		if err := g.cancelled(); err != nil {
+			return "", err
+		}
+		result, err := g.assemble(params, classes)
+		if err != nil {
+			return "", err
+		}
+		reason := runChecks(result, g.checks)
+		if reason == "" && !g.edgesAllowed([]rune(result), params) {
+			reason = "first or last character of a class not allowed there"
+		}
+		if reason == "" {
+			result = g.normalize(result)
+			if !g.weakForModel(result, params) {
+				return result, nil
+			}
+			reason = "estimated entropy below the minimum entropy"
+		}
+		if attempt > g.maxAttempts {
+			// The entropy model cannot be respected while building the password
+			if g.entropyModel != nil && g.minEntropy > 0 {
+				return "", ErrInsufficientEntropy
+			}
+			result, err = g.construct(params, classes, nil)
+			return g.normalize(result), err
+		}
+		g.notifyRetry(attempt, reason)
+	}
+}
+
+/*
+Function which tells if a password is below the minimal entropy according to the entropy model.
+	Method of Generator type
+	Without a model, the minimal entropy is verified once by charClasses
+	before generating the password, so false is returned.
+
+	Parameters:
+	-----------
+		password (string): generated password
+		params (GenerateConfig): parameters the password was generated with
+
+	Returns:
+	--------
+		bool - true if the password must be drawn again
+*/
+func (g *Generator) weakForModel(password string, params GenerateConfig) bool {
+	return g.entropyModel != nil && g.minEntropy > 0 && g.passwordEntropy(password, params) < g.minEntropy
+}
+
+/*
+Function which applies the normalization form (if any) to a password.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		password (string): password to normalize
+
+	Returns:
+	--------
+		string - normalized password
+*/
+func (g *Generator) normalize(password string) string {
+	if g.normalizer == nil {
+		return password
+	}
+	return g.normalizer.String(password)
+}
+
+/*
+Function which assembles a password from the characters of the given classes.
+	Method of Generator type
+	The characters of the classes are drawn then shuffled with Fisher-Yates,
+	so every arrangement of the classes in the password is equally likely.
+	With a placement bias or a limit of the runs, each character is instead
+	inserted at its position as it is drawn (uniform positions giving the
+	same uniform order), which lets the position be biased and a character
+	making a too long run be drawn again.
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+		classes ([]charClass): classes of characters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) assemble(params GenerateConfig, classes []charClass) (string, error) {
+	// Creation of the password in a buffer of the final size (the scratch
+	// buffer of the batch copies)
+	var result []rune
+	if g.scratch != nil {
+		result = g.scratch[:0]
+		defer func() { g.scratch = result }()
+	} else {
+		result = make([]rune, 0, params.Length)
+	}
+	insert := (g.maxRunLength > 0 && g.maxRunLength < params.Length) || slices.ContainsFunc(classes, func(class charClass) bool { return class.bias > 0 })
+	var attempt int
+	for _, class := range classes {
+		// Characters not used yet, drawn without replacement if the class is unique
+		var available []rune
+		if class.unique {
+			for _, r := range distinctChars(class.pool) {
+				if !slices.Contains(result, r) {
+					available = append(available, r)
+				}
+			}
+		}
+		rejected := 0
+		for i := 0; i < class.n; i++ {
+			// Build the password directly if the characters are always rejected
+			// or if the other classes used the characters of this one
+			if rejected > g.maxAttempts || (class.unique && len(available) == 0) {
+				return g.construct(params, classes, nil)
+			}
+			// Choice a character of the class
+			var r rune
+			var k int
+			var err error
+			if class.unique {
+				k, err = g.randomInt(len(available))
+				r = available[k]
+			} else {
+				var ch string
+				ch, err = g.randomElement(class.pool)
+				r, _ = utf8.DecodeRuneInString(ch)
+			}
+			if err != nil {
+				return "", err
+			}
+			if !insert {
+				result = append(result, r)
+			} else {
+				pos, err := g.biasedPosition(len(result), class.bias)
+				if err != nil {
+					return "", err
+				}
+				result = slices.Insert(result, pos, r)
+				// Not keep the insertion if it makes a too long run of the same character
+				if g.maxRunLength > 0 && runAt(result, pos) > g.maxRunLength {
+					result = slices.Delete(result, pos, pos+1)
+					attempt++
+					rejected++
+					g.notifyRetry(attempt, fmt.Sprintf("%s %q makes a run longer than %d", class.name, r, g.maxRunLength))
+					i--
+					continue
+				}
+			}
+			// Remove the character from the unused ones
+			if class.unique {
+				available[k] = available[len(available)-1]
+				available = available[:len(available)-1]
+			}
+			rejected = 0
+		}
+	}
+
+	// Shuffle the characters drawn class by class
+	if !insert {
+		for i := len(result) - 1; i > 0; i-- {
+			j, err := g.placementInt(i + 1)
+			if err != nil {
+				return "", err
+			}
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	return string(result), nil
+}
+
+/*
+Function which returns the characters the passwords are made of.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		allowUpper (bool): include uppercase in the letters
+
+	Returns:
+	--------
+		string, string, string - letters, digits and symbols
+*/
+func (g *Generator) pools(allowUpper bool) (letters, digits, symbols string) {
+	letters, digits, symbols = g.lowerLetters, g.digits, g.symbols
+	if allowUpper {
+		letters += g.upperLetters
+		// The uppercase letters were folded to lowercase letters by WithForceLowercase
+		if g.forceLowercase {
+			letters = string(distinctChars(letters))
+		}
+	}
+	return letters, digits, symbols
+}
+
+/*
+Function which verifies the parameters and returns the classes of characters of the password.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		[]charClass, error - classes in insertion order and the error if no password can be generated
+*/
+func (g *Generator) charClasses(params GenerateConfig) ([]charClass, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	length, numDigits, numSymbols := params.Length, params.NumDigits, params.NumSymbols
+	allowRepeat := params.AllowRepeat
+	uniqueSymbols := !allowRepeat || g.strictSymbols
+
+	// Get all possibles characters
+	letters, digits, symbols := g.pools(params.AllowUppercase)
+
+	// Verify if it is possible to generate a password
+	if numDigits < 0 || numSymbols < 0 {
+		return nil, fmt.Errorf("%w: numbers of digits and symbols must not be negative", ErrInvalidArgument)
+	}
+	chars := length - numDigits - numSymbols
+	if chars < 0 {
+		return nil, &GenerateError{
+			Reason:     fmt.Sprintf("requested %d digits and %d symbols but the length is %d", numDigits, numSymbols, length),
+			Length:     length,
+			NumDigits:  numDigits,
+			NumSymbols: numSymbols,
+			Err:        ErrExceedsTotalLength,
+		}
+	}
+	if !(params.UppercaseRatio >= 0 && params.UppercaseRatio <= 1) {
+		return nil, fmt.Errorf("%w: uppercase ratio %v not between 0 and 1", ErrInvalidArgument, params.UppercaseRatio)
+	}
+	if params.UppercaseRatio > 0 && !params.AllowUppercase {
+		return nil, fmt.Errorf("%w: uppercase ratio set but uppercase not allowed", ErrInvalidArgument)
+	}
+	if (chars > 0 && letters == "") || (numDigits > 0 && digits == "") || (numSymbols > 0 && symbols == "") {
+		return nil, ErrEmptyPool
+	}
+	if n := distinctCount(letters); !allowRepeat && chars > n {
+		return nil, exceedsAvailable(ErrLettersExceedsAvailable, params, "letters", chars, n)
+	}
+	if n := distinctCount(digits); !allowRepeat && numDigits > n {
+		return nil, exceedsAvailable(ErrDigitsExceedsAvailable, params, "digits", numDigits, n)
+	}
+	if n := distinctCount(symbols); uniqueSymbols && numSymbols > n {
+		return nil, exceedsAvailable(ErrSymbolsExceedsAvailable, params, "symbols", numSymbols, n)
+	}
+	if g.entropyModel == nil {
+		if err := g.verifyEntropy(g.Entropy(params)); err != nil {
+			return nil, err
+		}
+	}
+
+	classes := []charClass{
+		{"letter", letters, chars, !allowRepeat, 0},
+		{"digit", digits, numDigits, !allowRepeat, 0},
+		{"symbol", symbols, numSymbols, uniqueSymbols, g.symbolBias},
+	}
+
+	// Reserve a lowercase letter and, if allowed, an uppercase letter
+	if g.requireEachClass {
+		required := []charClass{{"lowercase letter", g.lowerLetters, 1, !allowRepeat, 0}}
+		if params.AllowUppercase && !g.forceLowercase {
+			required = append(required, charClass{"uppercase letter", g.upperLetters, 1, !allowRepeat, 0})
+		}
+		if chars < len(required) {
+			return nil, fmt.Errorf("%w: %d letters are needed to contain each enabled letter case, %d requested", ErrInvalidArgument, len(required), chars)
+		}
+		for _, class := range required {
+			if class.pool == "" {
+				return nil, ErrEmptyPool
+			}
+		}
+		classes[0].n -= len(required)
+		classes = append(required, classes...)
+	}
+
+	// Split the letters between lowercase and uppercase letters
+	if params.UppercaseRatio > 0 && !g.forceLowercase {
+		i := slices.IndexFunc(classes, func(class charClass) bool { return class.name == "letter" })
+		upper, err := g.uppercaseCount(classes[i].n, params.UppercaseRatio, allowRepeat)
+		if err != nil {
+			return nil, err
+		}
+		classes = slices.Replace(classes, i, i+1,
+			charClass{"lowercase letter", g.lowerLetters, classes[i].n - upper, !allowRepeat, 0},
+			charClass{"uppercase letter", g.upperLetters, upper, !allowRepeat, 0})
+	}
+
+	// Verify if a character of the password may start and end it
+	firstClasses, lastClasses := g.edgeClasses(params)
+	if length > 0 && (params.NoLeadingSymbol || params.NoTrailingSymbol) {
+		edgeLetters := 1
+		if length > 1 && params.NoLeadingSymbol && params.NoTrailingSymbol {
+			edgeLetters = 2
+		}
+		if chars < edgeLetters || (params.NoLeadingSymbol && firstClasses == "") || (params.NoTrailingSymbol && lastClasses == "") {
+			return nil, ErrEdgeClassNotAllowed
+		}
+	}
+	if length > 0 && (firstClasses != "" || lastClasses != "") {
+		// Count the characters which may be drawn for one of the edges
+		first, last, both, edgeChars := false, false, false, 0
+		for _, class := range classes {
+			if class.n == 0 {
+				continue
+			}
+			edge := false
+			for _, r := range class.pool {
+				f, l := g.edgeAllowed(r, firstClasses), g.edgeAllowed(r, lastClasses)
+				first, last, both, edge = first || f, last || l, both || f && l, edge || f || l
+			}
+			if edge {
+				edgeChars += class.n
+			}
+		}
+		if !first || !last || (length == 1 && !both) || (length > 1 && edgeChars < 2) {
+			return nil, ErrEdgeClassNotAllowed
+		}
+	}
+
+	return classes, nil
+}
+
+/*
+Function which draws the number of uppercase letters among the letters of a password.
+	Method of Generator type
+	Each letter is uppercase with the given probability. Without repeats, the
+	number is kept in the range the lowercase and uppercase letters allow.
+
+	Parameters:
+	-----------
+		n (int): number of letters
+		ratio (float64): probability of a letter to be uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		int, error - number of uppercase letters and the error if it was not drawn
+*/
+func (g *Generator) uppercaseCount(n int, ratio float64, allowRepeat bool) (int, error) {
+	upper := 0
+	for range n {
+		f, err := g.randomFloat()
+		if err != nil {
+			return 0, err
+		}
+		if f < ratio {
+			upper++
+		}
+	}
+	if !allowRepeat {
+		upper = max(min(upper, distinctCount(g.upperLetters)), n-distinctCount(g.lowerLetters))
+	}
+	return upper, nil
+}
+
+/*
+Function which tells if a character belongs to one of the given classes.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		r (rune): character to test
+		classes (string): classes among L, U, D and S, empty for all the classes
+
+	Returns:
+	--------
+		bool - true if the character belongs to one of the classes
+*/
+func (g *Generator) edgeAllowed(r rune, classes string) bool {
+	if classes == "" {
+		return true
+	}
+	sets := map[rune]string{'L': g.lowerLetters, 'U': g.upperLetters, 'D': g.digits, 'S': g.symbols}
+	for _, class := range classes {
+		if strings.ContainsRune(sets[class], r) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Function which returns the classes allowed at the start and at the end of a password.
+	Method of Generator type
+	The classes of WithAllowedFirstClasses and WithAllowedLastClasses are
+	restricted to the letters by NoLeadingSymbol and NoTrailingSymbol.
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, string - classes allowed first and last (empty for all the classes)
+			Note: a class restricted to no letter is returned empty too, charClasses rejecting it
+*/
+func (g *Generator) edgeClasses(params GenerateConfig) (first, last string) {
+	first, last = g.firstClasses, g.lastClasses
+	letters := func(classes string) string {
+		if classes == "" {
+			return "LU"
+		}
+		return filterPool(classes, func(r rune) bool { return r == 'L' || r == 'U' })
+	}
+	if params.NoLeadingSymbol {
+		first = letters(first)
+	}
+	if params.NoTrailingSymbol {
+		last = letters(last)
+	}
+	return first, last
+}
+
+/*
+Function which tells if the edges of a (partial) password respect the allowed classes.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		chars ([]rune): characters of the password, possibly only its beginning
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		bool - true if the first character and, once complete, the last one are allowed
+*/
+func (g *Generator) edgesAllowed(chars []rune, params GenerateConfig) bool {
+	first, last := g.edgeClasses(params)
+	if len(chars) > 0 && !g.edgeAllowed(chars[0], first) {
+		return false
+	}
+	return len(chars) < params.Length || len(chars) == 0 || g.edgeAllowed(chars[len(chars)-1], last)
+}
+
+/*
+Function which builds a password satisfying all the constraints by a randomized search.
+	Method of Generator type
+	Instead of drawing whole candidates and rejecting them, the password is
+	built character by character: every prefix must respect the repeats, the
+	run length limit and the checks, and the search goes back to the previous
+	character when no character fits. This finds a password for configurations
+	that rejection sampling almost never satisfies, at the price of a less
+	uniform distribution, so it is only used once rejection sampling failed.
+	The search is bounded to avoid exploring huge spaces without solution.
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+		classes ([]charClass): classes of characters of the password (not modified)
+		checks ([]check): checks the password must pass, besides the checks of the options
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if no password was found
+*/
+func (g *Generator) construct(params GenerateConfig, classes []charClass, checks []check) (string, error) {
+	classes = slices.Clone(classes)
+	checks = append(slices.Clone(g.checks), checks...)
+	type candidate struct {
+		class int
+		char  rune
+	}
+
+	result := make([]rune, 0, params.Length)
+	used := make(map[rune]int)
+	budget := g.maxAttempts * (params.Length + 1)
+	var search func() (bool, error)
+	search = func() (bool, error) {
+		if len(result) == params.Length {
+			return true, nil
+		}
+
+		// List the characters which can follow, in random order
+		var candidates []candidate
+		for c, class := range classes {
+			if class.n == 0 {
+				continue
+			}
+			for _, r := range distinctChars(class.pool) {
+				if !class.unique || used[r] == 0 {
+					candidates = append(candidates, candidate{c, r})
+				}
+			}
+		}
+		err := g.shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, cand := range candidates {
+			if budget == 0 {
+				return false, nil
+			}
+			budget--
+			if err := g.cancelled(); err != nil {
+				return false, err
+			}
+
+			// Keep the character only if the prefix respects the constraints
+			result = append(result, cand.char)
+			prefix := string(result)
+			if (g.maxRunLength == 0 || longestRun(prefix) <= g.maxRunLength) && g.edgesAllowed(result, params) && runChecks(prefix, checks) == "" {
+				classes[cand.class].n--
+				used[cand.char]++
+				ok, err := search()
+				if ok || err != nil {
+					return ok, err
+				}
+				classes[cand.class].n++
+				used[cand.char]--
+			}
+			result = result[:len(result)-1]
+		}
+		return false, nil
+	}
+
+	ok, err := search()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrCannotSatisfy
+	}
+	return string(result), nil
+}
+
+/*
+Function to generate a password once the given rate limiter allows it.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		ctx (context.Context): context cancelling the wait
+		limiter (Limiter): rate limiter shared by the callers
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated (the context error if it was cancelled)
+			Note: ErrInvalidArgument is returned if the limiter is nil
+*/
+func (g *Generator) GenerateThrottled(ctx context.Context, limiter Limiter, params GenerateConfig) (string, error) {
+	if limiter == nil {
+		return "", fmt.Errorf("%w: limiter must not be nil", ErrInvalidArgument)
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	return g.generate(params)
+}
+
+/*
+Function to generate a password and to transform it with a format function.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+		format (func(string) (string, error)): function transforming the password (e.g. to insert separators)
+
+	Returns:
+	--------
+		string, error - transformed password and the error if the password was not generated or transformed
+*/
+func (g *Generator) GenerateFormat(params GenerateConfig, format func(string) (string, error)) (string, error) {
+	pwd, err := g.generate(params)
+	if err != nil {
+		return "", err
+	}
+	return format(pwd)
+}
+
+/*
+Function to generate a password using only the characters which are both in the generator and in the given list.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		allowed (string): list of the allowed characters
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrEmptyPool, wrapped with the name of the class, is returned if no allowed character is left for a required class
+*/
+func (g *Generator) GenerateWithinAllowed(allowed string, length, numDigits, numSymbols int, allowUpper, allowRepeat bool) (string, error) {
+	isAllowed := func(r rune) bool {
+		return strings.ContainsRune(allowed, r)
+	}
+
+	// Restrict a copy of the generator to the allowed characters
+	restricted := *g
+	restricted.lowerLetters = filterPool(g.lowerLetters, isAllowed)
+	restricted.upperLetters = filterPool(g.upperLetters, isAllowed)
+	restricted.digits = filterPool(g.digits, isAllowed)
+	restricted.symbols = filterPool(g.symbols, isAllowed)
+
+	// Tell which class has no allowed character left
+	letters, digits, symbols := restricted.pools(allowUpper)
+	for _, class := range []struct {
+		name string
+		pool string
+		n    int
+	}{
+		{"letters", letters, length - numDigits - numSymbols},
+		{"digits", digits, numDigits},
+		{"symbols", symbols, numSymbols},
+	} {
+		if class.n > 0 && class.pool == "" {
+			return "", fmt.Errorf("%w: %s", ErrEmptyPool, class.name)
+		}
+	}
+
+	return restricted.Generate(length, numDigits, numSymbols, allowUpper, allowRepeat)
+}
+
+/*
+Function to generate a token along with a fresh salt of SaltSize bytes.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the token
+
+	Returns:
+	--------
+		string, []byte, error - token, salt and the error if they were not generated
+*/
+func (g *Generator) GenerateSaltedToken(params GenerateConfig) (token string, salt []byte, err error) {
+	token, err = g.generate(params)
+	if err != nil {
+		return "", nil, err
+	}
+	salt = make([]byte, SaltSize)
+	if _, err = io.ReadFull(g.reader, salt); err != nil {
+		return "", nil, err
+	}
+	return token, salt, nil
+}
+
+/*
+Function to generate a password which does not contain any of the given personal identifiers.
+	Method of Generator type
+	The comparison ignores the case and the identifiers shorter than 3
+	characters.
+
+	Parameters:
+	-----------
+		pii ([]string): personal identifiers (username, email, ...)
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if every attempt contained an identifier
+*/
+func (g *Generator) GenerateAvoidingPII(pii []string, params GenerateConfig) (string, error) {
+	return g.generateChecked(params, avoidSubstrings(pii, 3))
+}
+
+/*
+Function to generate passwords until one of them is accepted by the given function.
+	Method of Generator type
+	This is the general way to enforce a custom policy that the options of the
+	generator cannot express.
+
+	Parameters:
+	-----------
+		accept (func(string) bool): function telling if a password is acceptable
+		maxAttempts (int): maximal number of generated passwords (DefaultMaxAttempts if not positive)
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if no password was accepted
+*/
+func (g *Generator) GenerateUntil(accept func(string) bool, maxAttempts int, params GenerateConfig) (string, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = g.maxAttempts
+	}
+	return g.generateUntil(params, maxAttempts, func(password string) string {
+		if !accept(password) {
+			return "rejected by the accept function"
+		}
+		return ""
+	})
+}
+
+/*
+Function to generate passwords until one of them is accepted or the given duration elapsed.
+	Method of Generator type
+	Time-bounded counterpart of GenerateUntil.
+
+	Parameters:
+	-----------
+		d (time.Duration): maximal duration of the generation (must be positive)
+		accept (func(string) bool): function telling if a password is acceptable
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrTimeout is returned if no password was accepted in time
+*/
+func (g *Generator) GenerateWithTimeout(d time.Duration, accept func(string) bool, params GenerateConfig) (string, error) {
+	if d <= 0 {
+		return "", fmt.Errorf("%w: duration must be positive", ErrInvalidArgument)
+	}
+	deadline := time.Now().Add(d)
+	for attempt := 1; time.Now().Before(deadline); attempt++ {
+		pwd, err := g.generate(params)
+		if err != nil {
+			return "", err
+		}
+		if accept(pwd) {
+			return pwd, nil
+		}
+		g.notifyRetry(attempt, "rejected by the accept function")
+	}
+	return "", ErrTimeout
+}
+
+/*
+Function which generates passwords until one of them passes all the given checks.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+		checks (...check): checks the password must pass
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if no password passes the checks
+*/
+func (g *Generator) generateChecked(params GenerateConfig, checks ...check) (string, error) {
+	pwd, err := g.generateUntil(params, constructAfter, func(password string) string {
+		return runChecks(password, checks)
+	})
+	if !errors.Is(err, ErrCannotSatisfy) {
+		return pwd, err
+	}
+
+	// Build the password directly when the constraints are rarely satisfied
+	classes, err := g.charClasses(params)
+	if err != nil {
+		return "", err
+	}
+	return g.construct(params, classes, checks)
+}
+
+/*
+Function to generate a password matching a regular expression.
+	Method of Generator type
+	Passwords are generated with the given parameters until one of them
+	matches re, e.g. a policy such as `[0-9]` and `[A-Z]` in one expression.
+	The expression is not used to build the password, so an expression
+	rarely matched (or never, e.g. requiring more digits than numDigits) can
+	be slow: the number of attempts is capped (see WithMaxAttempts) so that
+	the method always returns.
+
+	Parameters:
+	-----------
+		re (*regexp.Regexp): regular expression the password must match
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if no password matched within the maximal number of attempts
+*/
+func (g *Generator) GenerateMatching(re *regexp.Regexp, length, numDigits, numSymbols int, allowUpper, allowRepeat bool) (string, error) {
+	if re == nil {
+		return "", fmt.Errorf("%w: nil regular expression", ErrInvalidArgument)
+	}
+	params := GenerateConfig{
+		Length:         length,
+		NumDigits:      numDigits,
+		NumSymbols:     numSymbols,
+		AllowUppercase: allowUpper,
+		AllowRepeat:    allowRepeat,
+	}
+	pwd, err := g.generateUntil(params, g.maxAttempts, func(password string) string {
+		if !re.MatchString(password) {
+			return "does not match " + re.String()
+		}
+		return ""
+	})
+	if errors.Is(err, ErrCannotSatisfy) {
+		return "", fmt.Errorf("%w: no password matching %q in %d attempts", err, re.String(), g.maxAttempts)
+	}
+	return pwd, err
+}
+
+/*
+Function which generates passwords until one of them is not rejected.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		params (GenerateConfig): parameters of the password
+		maxAttempts (int): maximal number of generated passwords
+		reject (func(string) string): function returning the reason why a password is rejected, empty to accept it
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if the maxAttempts passwords were rejected
+*/
+func (g *Generator) generateUntil(params GenerateConfig, maxAttempts int, reject func(string) string) (string, error) {
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := g.cancelled(); err != nil {
+			return "", err
+		}
+		pwd, err := g.generate(params)
+		if err != nil {
+			return "", err
+		}
+		reason := reject(pwd)
+		if reason == "" {
+			return pwd, nil
+		}
+		g.notifyRetry(attempt, reason)
+	}
+	return "", ErrCannotSatisfy
+}
+
+/*
+Function to generate a password which can be put in a URL without escaping.
+	Method of Generator type
+	The characters are drawn from the ASCII letters and digits of the generator
+	and from URLSafeSymbols.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateURLSafe(length int, allowRepeat bool) (string, error) {
+	isUnreserved := func(r rune) bool {
+		return r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}
+	pool := filterPool(g.lowerLetters+g.upperLetters+g.digits, isUnreserved) + URLSafeSymbols
+	return g.randomStringFrom(pool, length, allowRepeat)
+}
+
+/*
+Function to generate a password with a minimal number of lowercase letters.
+	Method of Generator type
+	The other letters may be lowercase or uppercase, so a password does not
+	end up mostly uppercase.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		minLower (int): minimal number of lowercase letters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateWithMinLowercase(length, minLower, numDigits, numSymbols int, allowRepeat bool) (string, error) {
+	if minLower < 0 || minLower+numDigits+numSymbols > length {
+		return "", fmt.Errorf("%w: minLower must not be negative and minLower+numDigits+numSymbols must not exceed length", ErrInvalidArgument)
+	}
+	params := GenerateConfig{
+		Length:         length,
+		NumDigits:      numDigits,
+		NumSymbols:     numSymbols,
+		AllowUppercase: true,
+		AllowRepeat:    allowRepeat,
+	}
+	classes, err := g.charClasses(params)
+	if err != nil {
+		return "", err
+	}
+
+	// Take the minimal lowercase letters out of the letters
+	lower, _, _ := g.pools(false)
+	if minLower > 0 && lower == "" {
+		return "", ErrEmptyPool
+	}
+	if !allowRepeat && minLower > distinctCount(lower) {
+		return "", ErrLettersExceedsAvailable
+	}
+	i := slices.IndexFunc(classes, func(class charClass) bool { return class.name == "letter" })
+	if classes[i].n < minLower {
+		return "", fmt.Errorf("%w: %d letters left for %d lowercase letters", ErrInvalidArgument, classes[i].n, minLower)
+	}
+	classes[i].n -= minLower
+	classes = append([]charClass{{"lowercase letter", lower, minLower, !allowRepeat, 0}}, classes...)
+	return g.generateClasses(params, classes)
+}
+
+/*
+Function to generate a password whose classes of characters follow the given weights.
+	Method of Generator type
+	Each character is a letter, a digit or a symbol with a probability
+	proportional to the weight of its class, so the proportions of the
+	classes only match the weights on average (e.g. 60, 25 and 15 for about
+	60% of letters). Without repeats, the draws exceeding the available
+	characters of a class are made again.
+
+	Parameters:
+	-----------
+		length (int): total number of characters (must be positive)
+		letterW (float64): weight of the letters
+		digitW (float64): weight of the digits
+		symbolW (float64): weight of the symbols
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateWeighted(length int, letterW, digitW, symbolW float64, allowUpper, allowRepeat bool) (string, error) {
+	if length < 1 {
+		return "", fmt.Errorf("%w: length must be positive", ErrInvalidArgument)
+	}
+	total := letterW + digitW + symbolW
+	if !(letterW >= 0 && digitW >= 0 && symbolW >= 0) || !(total > 0) || math.IsInf(total, 0) {
+		return "", fmt.Errorf("%w: weights must not be negative and must have a positive finite sum", ErrInvalidArgument)
+	}
+
+	params := GenerateConfig{Length: length, AllowUppercase: allowUpper, AllowRepeat: allowRepeat}
+	var err error
+	for attempt := 1; attempt <= g.maxAttempts; attempt++ {
+		// Draw the class of each character
+		params.NumDigits, params.NumSymbols = 0, 0
+		for range length {
+			f, err := g.randomFloat()
+			if err != nil {
+				return "", err
+			}
+			switch f *= total; {
+			case f < digitW:
+				params.NumDigits++
+			case f < digitW+symbolW:
+				params.NumSymbols++
+			}
+		}
+
+		var classes []charClass
+		classes, err = g.charClasses(params)
+		if err == nil {
+			return g.generateClasses(params, classes)
+		}
+		if !errors.Is(err, ErrLettersExceedsAvailable) && !errors.Is(err, ErrDigitsExceedsAvailable) && !errors.Is(err, ErrSymbolsExceedsAvailable) {
+			return "", err
+		}
+		g.notifyRetry(attempt, err.Error())
+	}
+	return "", err
+}
+
+/*
+Function to generate a password with minimal numbers of characters of each class.
+	Method of Generator type
+	Once the minimums are placed, the other characters are drawn uniformly
+	from all the sets, so each class may have more characters than its minimum.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		minLower (int): minimal number of lowercase letters
+		minUpper (int): minimal number of uppercase letters
+		minDigits (int): minimal number of digits
+		minSymbols (int): minimal number of symbols
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateWithMinimums(length int, minLower, minUpper, minDigits, minSymbols int, allowRepeat bool) (string, error) {
+	if minLower < 0 || minUpper < 0 || minDigits < 0 || minSymbols < 0 {
+		return "", fmt.Errorf("%w: minimums must not be negative", ErrInvalidArgument)
+	}
+	if minLower+minUpper+minDigits+minSymbols > length {
+		return "", fmt.Errorf("%w: the sum of the minimums (%d) exceeds the length (%d)", ErrInvalidArgument, minLower+minUpper+minDigits+minSymbols, length)
+	}
+	if err := g.Validate(); err != nil {
+		return "", err
+	}
+
+	// Get all possibles characters
+	lower, digits, symbols := g.pools(false)
+	upper := g.upperLetters
+	if g.forceLowercase {
+		upper = ""
+	}
+	all := string(distinctChars(lower + upper + digits + symbols))
+
+	// Verify if it is possible to generate a password
+	classes := []charClass{
+		{"lowercase letter", lower, minLower, !allowRepeat, 0},
+		{"uppercase letter", upper, minUpper, !allowRepeat, 0},
+		{"digit", digits, minDigits, !allowRepeat, 0},
+		{"symbol", symbols, minSymbols, !allowRepeat, g.symbolBias},
+		{"character", all, length - minLower - minUpper - minDigits - minSymbols, !allowRepeat, 0},
+	}
+	for _, class := range classes {
+		if class.n > 0 && class.pool == "" {
+			return "", ErrEmptyPool
+		}
+	}
+	if !allowRepeat {
+		switch {
+		case minLower > distinctCount(lower), minUpper > distinctCount(upper), minLower+minUpper > distinctCount(lower+upper):
+			return "", ErrLettersExceedsAvailable
+		case minDigits > distinctCount(digits):
+			return "", ErrDigitsExceedsAvailable
+		case minSymbols > distinctCount(symbols):
+			return "", ErrSymbolsExceedsAvailable
+		case length > utf8.RuneCountInString(all):
+			return "", ErrLengthExceedsAvailable
+		}
+	}
+
+	if g.entropyModel == nil {
+		// Choices inside each class and arrangements of the classes
+		bits := log2Factorial(length)
+		for _, class := range classes {
+			bits += drawEntropy(distinctCount(class.pool), class.n, allowRepeat) - log2Factorial(class.n)
+		}
+		if err := g.verifyEntropy(bits); err != nil {
+			return "", err
+		}
+	}
+
+	params := GenerateConfig{Length: length, AllowUppercase: upper != "", AllowRepeat: allowRepeat}
+	return g.generateClasses(params, classes)
+}
+
+/*
+Function to generate a password whose digits are neither repeated nor sequential.
+	Method of Generator type
+	Taken in their order of appearance (whatever the letters between them),
+	two consecutive digits never differ by one, so the password contains
+	neither 12 nor 98, even as 1a2. The letters may repeat.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		numDigits (int): number of digits to include (at most the number of digits of the generator)
+		allowUpper (bool): include uppercase
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if no such password was found
+*/
+func (g *Generator) GenerateStrictDigits(length, numDigits int, allowUpper bool) (string, error) {
+	if numDigits > distinctCount(g.digits) {
+		return "", ErrDigitsExceedsAvailable
+	}
+	params := GenerateConfig{
+		Length:         length,
+		NumDigits:      numDigits,
+		AllowUppercase: allowUpper,
+		AllowRepeat:    true,
+	}
+	return g.generateChecked(params, g.strictDigits)
+}
+
+/*
+Function which checks that the digits of a password are neither repeated nor sequential
+	Method of Generator type
+
+	Parameters:
+	-----------
+		password (string): password to check
+
+	Returns:
+	--------
+		string - reason why the password is rejected, empty if it is accepted
+*/
+func (g *Generator) strictDigits(password string) string {
+	seen := make(map[rune]bool)
+	var previous rune
+	for _, r := range password {
+		if !g.isDigit(r) {
+			continue
+		}
+		if seen[r] {
+			return fmt.Sprintf("digit %q repeated", r)
+		}
+		if previous != 0 && isASCIIDigit(r) && isASCIIDigit(previous) && (r-previous == 1 || previous-r == 1) {
+			return fmt.Sprintf("sequential digits %q and %q", previous, r)
+		}
+		seen[r] = true
+		previous = r
+	}
+	return ""
+}
+
+/*
+Function to generate a password with numbers of characters taken from Unicode categories or scripts.
+	Method of Generator type
+	Each quota requires a number of characters of the sets of the generator
+	belonging to a range table (e.g. unicode.Greek or unicode.Lu), the other
+	characters being drawn from all the sets. The characters are shuffled so
+	the categories are mixed.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		quotas (map[*unicode.RangeTable]int): number of characters by range table
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if the sets do not have enough characters of a table
+*/
+func (g *Generator) GenerateUnicodeQuotas(length int, quotas map[*unicode.RangeTable]int, allowRepeat bool) (string, error) {
+	pool := distinctChars(g.lowerLetters + g.upperLetters + g.digits + g.symbols)
+
+	// Sort the tables by first character so seeded generators are reproducible
+	tables := make([]*unicode.RangeTable, 0, len(quotas))
+	total := 0
+	for table, n := range quotas {
+		if n < 0 {
+			return "", fmt.Errorf("%w: quotas must not be negative", ErrInvalidArgument)
+		}
+		tables = append(tables, table)
+		total += n
+	}
+	if total > length {
+		return "", fmt.Errorf("%w: the quotas exceed the length", ErrInvalidArgument)
+	}
+	firstRune := func(t *unicode.RangeTable) rune {
+		if len(t.R16) > 0 {
+			return rune(t.R16[0].Lo)
+		}
+		if len(t.R32) > 0 {
+			return rune(t.R32[0].Lo)
+		}
+		return 0
+	}
+	slices.SortFunc(tables, func(a, b *unicode.RangeTable) int {
+		return int(firstRune(a) - firstRune(b))
+	})
+
+	// Draw the characters of each table, then the other ones
+	result := make([]rune, 0, length)
+	used := make(map[rune]bool)
+	draw := func(n int, keep func(rune) bool, name string) error {
+		var candidates []rune
+		for _, r := range pool {
+			if keep(r) && (allowRepeat || !used[r]) {
+				candidates = append(candidates, r)
+			}
+		}
+		if n > 0 && (len(candidates) == 0 || (!allowRepeat && n > len(candidates))) {
+			return fmt.Errorf("%w: %d characters of %s needed, %d available", ErrCannotSatisfy, n, name, len(candidates))
+		}
+		for i := 0; i < n; i++ {
+			k, err := g.randomInt(len(candidates))
+			if err != nil {
+				return err
+			}
+			result = append(result, candidates[k])
+			used[candidates[k]] = true
+			if !allowRepeat {
+				candidates = slices.Delete(candidates, k, k+1)
+			}
+		}
+		return nil
+	}
+	for _, table := range tables {
+		err := draw(quotas[table], func(r rune) bool { return unicode.Is(table, r) }, fmt.Sprintf("the table starting at %U", firstRune(table)))
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := draw(length-total, func(rune) bool { return true }, "the sets"); err != nil {
+		return "", err
+	}
+
+	err := g.shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+/*
+Function to generate a password with given characters at given positions.
+	Method of Generator type
+	The fixed characters count in the length but not in the numbers of digits
+	and symbols, and they are not taken into account to avoid repeats.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		fixed (map[int]rune): characters by position (from 0)
+		numDigits (int): number of digits to include in the free positions
+		numSymbols (int): number of symbols to include in the free positions
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateWithFixedChars(length int, fixed map[int]rune, numDigits, numSymbols int, allowUpper, allowRepeat bool) (string, error) {
+	// Verify the positions of the fixed characters
+	positions := make([]int, 0, len(fixed))
+	for i := range fixed {
+		if i < 0 || i >= length {
+			return "", fmt.Errorf("%w: position %d is outside the password", ErrInvalidArgument, i)
+		}
+		positions = append(positions, i)
+	}
+	sort.Ints(positions)
+
+	// Generate the free positions
+	free, err := g.Generate(length-len(fixed), numDigits, numSymbols, allowUpper, allowRepeat)
+	if err != nil {
+		return "", err
+	}
+
+	// Insert the fixed characters in order of position
+	result := []rune(free)
+	for _, i := range positions {
+		result = append(result[:i], append([]rune{fixed[i]}, result[i:]...)...)
+	}
+	return string(result), nil
+}
+
+/*
+Function to generate a password following a pattern, e.g. "UDDD-LLLL" for "A472-qwzk".
+	Method of Generator type
+	Each token of the pattern is replaced by a character of its class: L
+	(lowercase letter), U (uppercase letter), D (digit) or S (symbol). Any
+	other character is kept as is, and a backslash keeps the next character
+	as is (e.g. "\D" for a literal D).
+
+	Parameters:
+	-----------
+		pattern (string): tokens and literal characters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateFromPattern(pattern string) (string, error) {
+	pools := map[rune][]rune{
+		'L': []rune(g.lowerLetters),
+		'U': []rune(g.upperLetters),
+		'D': []rune(g.digits),
+		'S': []rune(g.symbols),
+	}
+
+	var b strings.Builder
+	escaped := false
+	bits := 0.0
+	for _, r := range pattern {
+		pool, isToken := pools[r]
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case !isToken:
+			b.WriteRune(r)
+		case len(pool) == 0:
+			return "", ErrEmptyPool
+		default:
+			c, err := g.randomRune(pool)
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(c)
+			bits += drawEntropy(len(pool), 1, true)
+		}
+	}
+	// The literal characters do not add any entropy
+	if err := g.verifyEntropy(bits); err != nil {
+		return "", err
+	}
+	// Keep a trailing backslash
+	if escaped {
+		b.WriteRune('\\')
+	}
+	return b.String(), nil
+}
+
+/*
+Function to generate a password following a mask with minimal numbers of characters by class.
+	Method of Generator type
+	Each character of the mask gives the class of the character at its
+	position: l (lowercase letter), u (uppercase letter), d (digit), s (symbol)
+	or a (any of them). The characters may repeat. The minimums count the
+	whole password, the missing characters being placed at a positions.
+
+	Parameters:
+	-----------
+		mask (string): classes of the characters by position
+		minClasses (map[rune]int): minimal number of characters by class (l, u, d or s)
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrCannotSatisfy is returned if the a positions cannot hold the missing characters
+*/
+func (g *Generator) GenerateMaskWithMinimums(mask string, minClasses map[rune]int) (string, error) {
+	pools := map[rune]string{'l': g.lowerLetters, 'u': g.upperLetters, 'd': g.digits, 's': g.symbols}
+	pools['a'] = pools['l'] + pools['u'] + pools['d'] + pools['s']
+
+	// Count the positions of each class
+	classes := []rune(mask)
+	counts := make(map[rune]int)
+	var anyPositions []int
+	for i, class := range classes {
+		if _, ok := pools[class]; !ok {
+			return "", fmt.Errorf("%w: unknown mask class %q at position %d (expected l, u, d, s or a)", ErrInvalidArgument, class, i)
+		}
+		counts[class]++
+		if class == 'a' {
+			anyPositions = append(anyPositions, i)
+		}
+	}
+
+	// Verify if the minimums fit in the a positions
+	missing := 0
+	for _, class := range "luds" {
+		n := minClasses[class]
+		if n < 0 {
+			return "", fmt.Errorf("%w: minimum of class %q must not be negative", ErrInvalidArgument, class)
+		}
+		missing += max(0, n-counts[class])
+	}
+	for class := range minClasses {
+		if !strings.ContainsRune("luds", class) {
+			return "", fmt.Errorf("%w: unknown minimum class %q (expected l, u, d or s)", ErrInvalidArgument, class)
+		}
+	}
+	if missing > len(anyPositions) {
+		return "", fmt.Errorf("%w: the minimums need %d more characters but the mask has %d a positions", ErrCannotSatisfy, missing, len(anyPositions))
+	}
+
+	// Give the class of the missing characters to random a positions
+	err := g.shuffle(len(anyPositions), func(i, j int) {
+		anyPositions[i], anyPositions[j] = anyPositions[j], anyPositions[i]
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, class := range "luds" {
+		for n := minClasses[class] - counts[class]; n > 0; n-- {
+			classes[anyPositions[0]] = class
+			anyPositions = anyPositions[1:]
+		}
+	}
+
+	// Draw the characters
+	result := make([]rune, len(classes))
+	for i, class := range classes {
+		if pools[class] == "" {
+			return "", ErrEmptyPool
+		}
+		r, err := g.randomRune([]rune(pools[class]))
+		if err != nil {
+			return "", err
+		}
+		result[i] = r
+	}
+	return string(result), nil
+}
+
+/*
+Function to generate passwords and to write them in a JSON file.
+	Method of Generator type
+	The file contains an array of {"id": ..., "password": ...} objects (ids
+	starting at 1), encoded one by one so large counts are not kept in
+	memory, and it is only readable by its owner (0600 permissions).
+
+	Parameters:
+	-----------
+		path (string): path of the file
+		count (int): number of passwords
+		params (GenerateConfig): parameters of the passwords
+		force (bool): overwrite the file if it already exists
+
+	Returns:
+	--------
+		error - the error if the file was not written
+*/
+func (g *Generator) GenerateToJSONFile(path string, count int, params GenerateConfig, force bool) (err error) {
+	if count < 0 {
+		return fmt.Errorf("%w: count must not be negative", ErrInvalidArgument)
+	}
+
+	// Create the file, without replacing an existing one unless forced
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if err = f.Chmod(0600); err != nil {
+		return err
+	}
+
+	// Write the passwords one by one
+	w := bufio.NewWriter(f)
+	if _, err = w.WriteString("["); err != nil {
+		return err
+	}
+	for i := 1; i <= count; i++ {
+		pwd, err := g.generate(params)
+		if err != nil {
+			return err
+		}
+		entry, err := json.Marshal(struct {
+			ID       int    `json:"id"`
+			Password string `json:"password"`
+		}{i, pwd})
+		if err != nil {
+			return err
+		}
+		if i > 1 {
+			if _, err = w.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if _, err = w.WriteString("\n  "); err != nil {
+			return err
+		}
+		if _, err = w.Write(entry); err != nil {
+			return err
+		}
+	}
+	if _, err = w.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+/*
+Function to generate a password absent from a history file and to append it to the file, e.g. for rotation scripts.
+	Method of Generator type
+	The history file contains one password per line, a missing file being an
+	empty history. The new content is written to a temporary file (0600
+	permissions) which then replaces the history, so the history is never
+	left half written.
+
+	Parameters:
+	-----------
+		historyPath (string): path of the history file
+		params (GenerateParams): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated or the history not updated
+			Note: ErrCannotSatisfy is returned if no password absent from the history was found
+*/
+func (g *Generator) GenerateNotInFile(historyPath string, params GenerateParams) (string, error) {
+	// Read the history
+	data, err := os.ReadFile(historyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	history := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		history[strings.TrimSuffix(line, "\r")] = true
+	}
+
+	pwd, err := g.generateUntil(params, g.maxAttempts, func(password string) string {
+		if history[password] {
+			return "password already in the history"
+		}
+		return ""
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Write the new history next to the old one then replace it
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	data = append(data, pwd+"\n"...)
+	f, err := os.CreateTemp(filepath.Dir(historyPath), "."+filepath.Base(historyPath)+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	if err = os.Rename(f.Name(), historyPath); err != nil {
+		return "", err
+	}
+	return pwd, nil
+}
+
+/*
+Function to generate a password for each entry and to write them as CSV, e.g. to import them in a password manager.
+	Method of Generator type
+	The CSV starts with the header name,username,password, then has one row
+	per entry.
+
+	Parameters:
+	-----------
+		w (io.Writer): writer of the CSV
+		entries ([]struct{ Name, Username string }): name and username of the rows
+		params (GenerateConfig): parameters of the passwords
+
+	Returns:
+	--------
+		error - error if a password was not generated or the CSV not written
+*/
+func (g *Generator) GenerateCSV(w io.Writer, entries []struct{ Name, Username string }, params GenerateConfig) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "username", "password"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		pwd, err := g.generate(params)
+		if err != nil {
+			return err
+		}
+		if err = cw.Write([]string{entry.Name, entry.Username, pwd}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+/*
+Function to generate several passwords with the same parameters.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		count (int): number of passwords
+		params (GenerateConfig): parameters of the passwords
+
+	Returns:
+	--------
+		[]string, error - passwords and the error if they were not generated
+*/
+func (g *Generator) GenerateMany(count int, params GenerateConfig) ([]string, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("%w: count must not be negative", ErrInvalidArgument)
+	}
+	batch := g.batch(params.Length)
+	passwords := make([]string, count)
+	for i := range passwords {
+		pwd, err := batch.generate(params)
+		if err != nil {
+			return nil, err
+		}
+		passwords[i] = pwd
+	}
+	return passwords, nil
+}
+
+/*
+Function to generate several distinct passwords with the required arguments.
+	Method of Generator type
+	Each password is generated as by Generate, and generated again when it is
+	already in the batch.
+
+	Parameters:
+	-----------
+		count (int): number of passwords
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		[]string, error - passwords and the error if they were not generated
+			Note: ErrTooFewDistinct is returned if a password collided maxAttempts times in a row
+*/
+func (g *Generator) GenerateN(count, length, numDigits, numSymbols int, allowUpper, allowRepeat bool) ([]string, error) {
+	return g.GenerateNContext(context.Background(), count, length, numDigits, numSymbols, allowUpper, allowRepeat)
+}
+
+/*
+Function to generate several distinct passwords which can be cancelled by a context.
+	Method of Generator type
+	Same as GenerateN, the context being checked between the passwords and
+	between the attempts.
+
+	Parameters:
+	-----------
+		ctx (context.Context): context cancelling the generation
+		count (int): number of passwords
+		length (int): total number of characters
+		numDigits (int): number of digits to include
+		numSymbols (int): number of symbols to include
+		allowUpper (bool): include uppercase
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		[]string, error - passwords and the error if they were not generated (the context error if it was cancelled)
+			Note: ErrTooFewDistinct is returned if a password collided maxAttempts times in a row
+*/
+func (g *Generator) GenerateNContext(ctx context.Context, count, length, numDigits, numSymbols int, allowUpper, allowRepeat bool) ([]string, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("%w: count must not be negative", ErrInvalidArgument)
+	}
+	params := GenerateConfig{
+		Length:         length,
+		NumDigits:      numDigits,
+		NumSymbols:     numSymbols,
+		AllowUppercase: allowUpper,
+		AllowRepeat:    allowRepeat,
+	}
+
+	batch := g.withContext(ctx).batch(length)
+	passwords := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	for len(passwords) < count {
+		collisions := 0
+		pwd, err := batch.generateUntil(params, g.maxAttempts, func(password string) string {
+			if seen[password] {
+				collisions++
+				return "already in the batch"
+			}
+			return ""
+		})
+		// Every attempt collided (otherwise no password satisfies the constraints at all)
+		if errors.Is(err, ErrCannotSatisfy) && collisions == g.maxAttempts {
+			return nil, fmt.Errorf("%w: only %d found", ErrTooFewDistinct, len(passwords))
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen[pwd] = true
+		passwords = append(passwords, pwd)
+	}
+	return passwords, nil
+}
+
+/*
+Function to generate several passwords with the same parameters using several goroutines.
+	Method of Generator type
+	The passwords are returned in the same order as the indexes they were
+	drawn for. A custom random source, the seeded placements and the retry
+	observer are shared by the workers and called under a lock.
+
+	Parameters:
+	-----------
+		count (int): number of passwords
+		workers (int): number of goroutines (runtime.NumCPU() if not positive)
+		params (GenerateConfig): parameters of the passwords
+
+	Returns:
+	--------
+		[]string, error - passwords and the error if they were not generated
+*/
+func (g *Generator) GenerateManyParallel(count, workers int, params GenerateConfig) ([]string, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("%w: count must not be negative", ErrInvalidArgument)
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, count)
+
+	shared := g.concurrent()
+	passwords := make([]string, count)
+	errs := make([]error, workers)
+	var next atomic.Int64
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch := shared.batch(params.Length)
+			for !failed.Load() {
+				// Take the next index to fill
+				i := int(next.Add(1)) - 1
+				if i >= count {
+					return
+				}
+				pwd, err := batch.generate(params)
+				if err != nil {
+					errs[w] = err
+					failed.Store(true)
+					return
+				}
+				passwords[i] = pwd
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return passwords, nil
+}
+
+/*
+Function to generate several passwords whose lengths follow a normal distribution.
+	Method of Generator type
+	Meant for test corpora mimicking real passwords. Each length is drawn from
+	the normal distribution, rounded and clamped between the number of digits
+	and symbols (at least 1) and meanLen+4*stdDev. The Length field of params
+	is ignored.
+
+	Parameters:
+	-----------
+		count (int): number of passwords
+		meanLen (float64): mean length (positive)
+		stdDev (float64): standard deviation of the length (not negative)
+		params (GenerateConfig): parameters of the passwords
+
+	Returns:
+	--------
+		[]string, error - passwords and the error if they were not generated
+*/
+func (g *Generator) GenerateGaussianLengths(count int, meanLen, stdDev float64, params GenerateConfig) ([]string, error) {
+	if count < 0 || !(meanLen > 0) || !(stdDev >= 0) {
+		return nil, fmt.Errorf("%w: count and stdDev must not be negative, meanLen must be positive", ErrInvalidArgument)
+	}
+	minLen := max(1, params.NumDigits+params.NumSymbols)
+	maxLen := max(minLen, int(meanLen+4*stdDev))
+
+	passwords := make([]string, count)
+	for i := range passwords {
+		// Draw a standard normal value (Box-Muller transform)
+		u1, err := g.randomFloat()
+		if err != nil {
+			return nil, err
+		}
+		u2, err := g.randomFloat()
+		if err != nil {
+			return nil, err
+		}
+		z := math.Sqrt(-2*math.Log(1-u1)) * math.Cos(2*math.Pi*u2)
+
+		params.Length = min(max(int(math.Round(meanLen+z*stdDev)), minLen), maxLen)
+		pwd, err := g.generate(params)
+		if err != nil {
+			return nil, err
+		}
+		passwords[i] = pwd
+	}
+	return passwords, nil
+}
+
+/*
+Function to generate several passwords sorted in lexicographic order.
+	Method of Generator type
+	The passwords are as random as with GenerateMany, only their order is
+	deterministic, which eases the comparison of generated sets in tests.
+
+	Parameters:
+	-----------
+		count (int): number of passwords
+		params (GenerateConfig): parameters of the passwords
+
+	Returns:
+	--------
+		[]string, error - sorted passwords and the error if they were not generated
+*/
+func (g *Generator) GenerateManySorted(count int, params GenerateConfig) ([]string, error) {
+	passwords, err := g.GenerateMany(count, params)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(passwords)
+	return passwords, nil
+}
+
+/*
+Function to derive the password of the given index from a master seed.
+	Method of Generator type
+	The password is not random: the same seed, index and parameters always
+	give the same password (different indexes give different passwords), so
+	anyone knowing the seed can derive all the passwords. The randomness of the
+	generation is replaced by a ChaCha8 stream keyed with HKDF-SHA256 of the
+	seed, using the index as context.
+
+	Parameters:
+	-----------
+		seed ([]byte): master seed (non-empty, it should be secret and random)
+		index (uint32): index of the password
+		params (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not derived
+*/
+func (g *Generator) DerivePassword(seed []byte, index uint32, params GenerateConfig) (string, error) {
+	if len(seed) == 0 {
+		return "", fmt.Errorf("%w: seed must not be empty", ErrInvalidArgument)
+	}
+	key, err := hkdf.Key(sha256.New, seed, nil, fmt.Sprintf("passwordgenerator derive %d", index), 32)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate with a copy of the generator reading the derived stream
+	return g.derived(key).generate(params)
+}
+
+/*
+Function to turn a remembered base into a different password for each site.
+	Method of Generator type
+	The characters of the base and of the site tag are interleaved, then
+	extraDigits digits derived from both (HKDF-SHA256 of the base, with the
+	site tag as context, keying a ChaCha8 stream) are appended. The result is
+	not random: the same base and site tag always give the same password, so
+	anyone learning the base can compute the passwords of every site.
+
+	Parameters:
+	-----------
+		base (string): remembered base (non-empty)
+		siteTag (string): tag of the site, e.g. its domain (non-empty)
+		extraDigits (int): number of derived digits to append (not negative)
+
+	Returns:
+	--------
+		string, error - password of the site and the error if it was not derived
+*/
+func (g *Generator) MutateBase(base, siteTag string, extraDigits int) (string, error) {
+	if base == "" || siteTag == "" || extraDigits < 0 {
+		return "", fmt.Errorf("%w: base and siteTag must not be empty, extraDigits must not be negative", ErrInvalidArgument)
+	}
+	if extraDigits > 0 && g.digits == "" {
+		return "", ErrEmptyPool
+	}
+
+	// Interleave the base and the site tag
+	b, t := []rune(base), []rune(siteTag)
+	result := make([]rune, 0, len(b)+len(t)+extraDigits)
+	for i := 0; i < max(len(b), len(t)); i++ {
+		if i < len(b) {
+			result = append(result, b[i])
+		}
+		if i < len(t) {
+			result = append(result, t[i])
+		}
+	}
+	if extraDigits == 0 {
+		return string(result), nil
+	}
+
+	// Draw the digits from the stream derived from the base and the site tag
+	key, err := hkdf.Key(sha256.New, []byte(base), nil, "passwordgenerator mutate "+siteTag, 32)
+	if err != nil {
+		return "", err
+	}
+	digits, err := g.derived(key).randomString(g.digits, extraDigits)
+	if err != nil {
+		return "", err
+	}
+	return string(result) + digits, nil
+}
+
+/*
+Function to generate a password made of pronounceable syllables followed by digits and symbols.
+	Method of Generator type
+	Each syllable is a consonant followed by a vowel (e.g. "bokanu47!"), so
+	the start of the password is easy to remember while the end adds the
+	digits and symbols of the generator.
+
+	Parameters:
+	-----------
+		syllableCount (int): number of syllables (at least 1)
+		digitCount (int): number of digits after the syllables
+		symbolCount (int): number of symbols after the digits
+		allowUpper (bool): capitalize the first letter
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateHybrid(syllableCount, digitCount, symbolCount int, allowUpper bool) (string, error) {
+	if syllableCount < 1 || digitCount < 0 || symbolCount < 0 {
+		return "", fmt.Errorf("%w: syllableCount must be positive, digitCount and symbolCount must not be negative", ErrInvalidArgument)
+	}
+	if (digitCount > 0 && g.digits == "") || (symbolCount > 0 && g.symbols == "") {
+		return "", ErrEmptyPool
+	}
+
+	syllables, err := g.syllables(syllableCount)
+	if err != nil {
+		return "", err
+	}
+	if allowUpper && !g.forceLowercase {
+		syllables = strings.ToUpper(syllables[:1]) + syllables[1:]
+	}
+	digits, err := g.randomString(g.digits, digitCount)
+	if err != nil {
+		return "", err
+	}
+	symbols, err := g.randomString(g.symbols, symbolCount)
+	if err != nil {
+		return "", err
+	}
+	return syllables + digits + symbols, nil
+}
+
+/*
+Function to generate a password made of random lowercase chunks joined by separators.
+	Method of Generator type
+	Each separator is randomly chosen in the given list, which defaults to
+	"-", "." and "_" when it is empty.
+
+	Parameters:
+	-----------
+		chunks (int): number of chunks (at least 1)
+		chunkLen (int): number of letters of each chunk (at least 1)
+		separators ([]string): separators to choose from
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateChunkedMemorable(chunks, chunkLen int, separators []string) (string, error) {
+	if chunks < 1 || chunkLen < 1 {
+		return "", fmt.Errorf("%w: chunks and chunkLen must be positive", ErrInvalidArgument)
+	}
+	if g.lowerLetters == "" {
+		return "", ErrEmptyPool
+	}
+	if len(separators) == 0 {
+		separators = defaultSeparators
+	}
+
+	var b strings.Builder
+	for i := 0; i < chunks; i++ {
+		// Choice the separator
+		if i > 0 {
+			n, err := g.randomInt(len(separators))
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(separators[n])
+		}
+		// Choice the letters of the chunk
+		chunk, err := g.randomString(g.lowerLetters, chunkLen)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(chunk)
+	}
+	return b.String(), nil
+}
+
+/*
+Function which generates pronounceable syllables, each made of a consonant and a vowel.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		n (int): number of syllables
+
+	Returns:
+	--------
+		string, error - syllables and the error if they were not generated
+*/
+func (g *Generator) syllables(n int) (string, error) {
+	b := make([]byte, 0, 2*n)
+	for i := 0; i < n; i++ {
+		c, err := g.randomInt(len(consonants))
+		if err != nil {
+			return "", err
+		}
+		v, err := g.randomInt(len(vowels))
+		if err != nil {
+			return "", err
+		}
+		b = append(b, consonants[c], vowels[v])
+	}
+	return string(b), nil
+}
+
+/*
+Function to generate two related secrets sharing few characters at the same positions.
+	Method of Generator type
+	The second password is generated again until it has at most
+	maxSharedChars characters equal to the first one at the same position.
+
+	Parameters:
+	-----------
+		length (int): total number of characters, replacing params.Length
+		maxSharedChars (int): maximal number of positions holding the same character
+		params (GenerateConfig): parameters of the passwords
+
+	Returns:
+	--------
+		string, string, error - passwords and the error if they were not generated
+			Note: ErrCannotSatisfy is returned if no second password was found
+*/
+func (g *Generator) GeneratePair(length, maxSharedChars int, params GenerateConfig) (a, b string, err error) {
+	if maxSharedChars < 0 {
+		return "", "", fmt.Errorf("%w: maxSharedChars must not be negative", ErrInvalidArgument)
+	}
+	params.Length = length
+	a, err = g.generate(params)
+	if err != nil {
+		return "", "", err
+	}
+
+	first := []rune(a)
+	b, err = g.generateUntil(params, g.maxAttempts, func(password string) string {
+		// Count the positions holding the same character, the positions
+		// beyond the shorter password (e.g. after a normalization) being
+		// different
+		second := []rune(password)
+		shared := 0
+		for i := range min(len(first), len(second)) {
+			if second[i] == first[i] {
+				shared++
+			}
+		}
+		if shared > maxSharedChars {
+			return fmt.Sprintf("%d characters shared with the first password", shared)
+		}
+		return ""
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
+
+/*
+Function to generate a password made of the QRAlphanumeric characters.
+	Method of Generator type
+	A QR code holding only these characters uses the alphanumeric mode, which
+	is more compact than the byte mode, so the password can be shared with a
+	smaller QR code.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+*/
+func (g *Generator) GenerateQRFriendly(length int, allowRepeat bool) (string, error) {
+	return g.randomStringFrom(QRAlphanumeric, length, allowRepeat)
+}
+
+/*
+Function to generate a password following the rules of a compliance profile.
+	Method of Generator type
+	The available profiles are "nist-800-63b" (NIST SP 800-63B, at least 8
+	characters) and "pci-dss-4.0" (PCI DSS 4.0, at least 12 characters with
+	letters and digits). The note explains the rules which were applied.
+
+	Parameters:
+	-----------
+		profile (string): name of the profile
+		length (int): total number of characters (at least the minimum of the profile)
+
+	Returns:
+	--------
+		string, string, error - password, note and the error if the password was not generated
+			Note: ErrUnknownProfile is returned if the profile does not exist
+*/
+func (g *Generator) GenerateCompliant(profile string, length int) (password string, note string, err error) {
+	p, ok := complianceProfiles[profile]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q", ErrUnknownProfile, profile)
+	}
+	if length < p.minLength {
+		return "", "", fmt.Errorf("%w: profile %s requires at least %d characters", ErrInvalidArgument, profile, p.minLength)
+	}
+	password, err = p.generate(g, length)
+	if err != nil {
+		return "", "", err
+	}
+	return password, p.note, nil
+}
+
+/*
+Function to generate a string by a random walk on a matrix of transitions between characters.
+	Method of Generator type
+	Meant for test data having the local structure of human passwords. The
+	walk starts at the start character, then each character is drawn with a
+	probability proportional to the weight of its transition from the previous
+	one. The weights of a character do not need to sum to 1.
+
+	Parameters:
+	-----------
+		length (int): total number of characters, start included (positive)
+		transitions (map[rune]map[rune]float64): weights of the next characters by character
+		start (rune): first character
+
+	Returns:
+	--------
+		string, error - string and the error if the string was not generated
+			Note: ErrCannotSatisfy is returned if the walk reaches a character without transition
+*/
+func (g *Generator) GenerateMarkov(length int, transitions map[rune]map[rune]float64, start rune) (string, error) {
+	if length < 1 {
+		return "", fmt.Errorf("%w: length must be positive", ErrInvalidArgument)
+	}
+	for from, next := range transitions {
+		for to, weight := range next {
+			if !(weight >= 0) || math.IsInf(weight, 1) {
+				return "", fmt.Errorf("%w: weight of %q -> %q must be a finite non-negative number", ErrInvalidArgument, from, to)
+			}
+		}
+	}
+
+	result := []rune{start}
+	for len(result) < length {
+		state := result[len(result)-1]
+		// Sort the next characters so seeded generators are reproducible
+		next := make([]rune, 0, len(transitions[state]))
+		total := 0.0
+		for to, weight := range transitions[state] {
+			if weight > 0 {
+				next = append(next, to)
+				total += weight
+			}
+		}
+		if len(next) == 0 {
+			return "", fmt.Errorf("%w: no transition from %q", ErrCannotSatisfy, state)
+		}
+		slices.Sort(next)
+
+		// Draw the next character according to the weights
+		f, err := g.randomFloat()
+		if err != nil {
+			return "", err
+		}
+		target := f * total
+		chosen := next[len(next)-1]
+		for _, to := range next {
+			target -= transitions[state][to]
+			if target < 0 {
+				chosen = to
+				break
+			}
+		}
+		result = append(result, chosen)
+	}
+	return string(result), nil
+}
+
+/*
+Function to generate a password cut into parts, e.g. to print it on both sides of a card.
+	Method of Generator type
+	The characters are drawn from all the sets of the generator. The parts
+	are consecutive pieces of the password whose lengths differ by at most
+	one, so joining them in order gives the password back. This is not a
+	secret sharing scheme: each part reveals its characters.
+
+	Parameters:
+	-----------
+		length (int): total number of characters
+		parts (int): number of parts, between 1 and length
+
+	Returns:
+	--------
+		[]string, string, error - parts, whole password and the error if the password was not generated
+*/
+func (g *Generator) GenerateSplit(length, parts int) ([]string, string, error) {
+	if parts < 1 || parts > length {
+		return nil, "", fmt.Errorf("%w: parts must be between 1 and length", ErrInvalidArgument)
+	}
+	pool := g.lowerLetters + g.upperLetters + g.digits + g.symbols
+	if pool == "" {
+		return nil, "", ErrEmptyPool
+	}
+	if err := g.verifyEntropy(drawEntropy(distinctCount(pool), length, true)); err != nil {
+		return nil, "", err
+	}
+	password, err := g.randomString(pool, length)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Cut the password, the first parts taking the remaining characters
+	chars := []rune(password)
+	shares := make([]string, parts)
+	for i := range shares {
+		n := length / parts
+		if i < length%parts {
+			n++
+		}
+		shares[i], chars = string(chars[:n]), chars[n:]
+	}
+	return shares, password, nil
+}
+
+/*
+Function to generate a grid of distinct codes, e.g. for a printed backup card.
+	Method of Generator type
+	The codes are drawn from SafeAlphabet so they can be read back without
+	confusion.
+
+	Parameters:
+	-----------
+		rows (int): number of rows of the grid
+		cols (int): number of columns of the grid
+		codeLen (int): number of characters of each code
+
+	Returns:
+	--------
+		[][]string, error - grid of codes and the error if the grid was not generated
+*/
+func (g *Generator) GenerateGrid(rows, cols, codeLen int) ([][]string, error) {
+	if rows < 1 || cols < 1 || codeLen < 1 {
+		return nil, fmt.Errorf("%w: rows, cols and codeLen must be positive", ErrInvalidArgument)
+	}
+
+	codes, err := g.distinctCodes(SafeAlphabet, rows*cols, codeLen)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cut the codes into rows (capped, so appending to a row does not overwrite the next one)
+	grid := make([][]string, rows)
+	for r := range grid {
+		grid[r] = codes[r*cols : (r+1)*cols : (r+1)*cols]
+	}
+	return grid, nil
+}
+
+/*
+Function to generate identifiers for several shards, each shard having its own prefix.
+	Method of Generator type
+	The body of an identifier is made of lowercase letters and digits, and the
+	bodies are distinct inside a shard.
+
+	Parameters:
+	-----------
+		prefixes ([]string): distinct prefixes of the shards
+		perPrefix (int): number of identifiers per shard
+		bodyLen (int): number of characters after the prefix
+
+	Returns:
+	--------
+		map[string][]string, error - identifiers by prefix and the error if they were not generated
+*/
+func (g *Generator) GenerateShardedBatch(prefixes []string, perPrefix, bodyLen int) (map[string][]string, error) {
+	if len(prefixes) == 0 || perPrefix < 1 || bodyLen < 1 {
+		return nil, fmt.Errorf("%w: prefixes must not be empty, perPrefix and bodyLen must be positive", ErrInvalidArgument)
+	}
+
+	batch := make(map[string][]string, len(prefixes))
+	for _, prefix := range prefixes {
+		if _, ok := batch[prefix]; ok {
+			return nil, fmt.Errorf("%w: duplicate prefix %q", ErrInvalidArgument, prefix)
+		}
+		bodies, err := g.distinctCodes(g.lowerLetters+g.digits, perPrefix, bodyLen)
+		if err != nil {
+			return nil, fmt.Errorf("prefix %q: %w", prefix, err)
+		}
+		for i := range bodies {
+			bodies[i] = prefix + bodies[i]
+		}
+		batch[prefix] = bodies
+	}
+	return batch, nil
+}
+
+/*
+Function to generate a username made of lowercase letters and digits.
+	Method of Generator type
+	The username starts with a letter, never contains more than 2 consecutive
+	digits and its length is randomly chosen between minLen and maxLen.
+
+	Parameters:
+	-----------
+		minLen (int): minimal number of characters (at least 1)
+		maxLen (int): maximal number of characters
+
+	Returns:
+	--------
+		string, error - username and the error if the username was not generated
+*/
+func (g *Generator) GenerateUsername(minLen, maxLen int) (string, error) {
+	if minLen < 1 || maxLen < minLen {
+		return "", fmt.Errorf("%w: minLen must be positive and not greater than maxLen", ErrInvalidArgument)
+	}
+	if g.lowerLetters == "" {
+		return "", ErrEmptyPool
+	}
+	letters := []rune(g.lowerLetters)
+	alphanumerics := []rune(g.lowerLetters + g.digits)
+
+	// Choice the length
+	n, err := g.randomInt(maxLen - minLen + 1)
+	if err != nil {
+		return "", err
+	}
+	username := make([]rune, minLen+n)
+
+	for i := range username {
+		// Only a letter can start the username or follow 2 digits
+		pool := alphanumerics
+		if i == 0 || (i >= 2 && g.isDigit(username[i-1]) && g.isDigit(username[i-2])) {
+			pool = letters
+		}
+		username[i], err = g.randomRune(pool)
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(username), nil
+}
+
+/*
+Function to generate a PIN made of digits only, e.g. for a phone or a bank card.
+	Method of Generator type
+	The digits are drawn uniformly from the digits of the generator.
+
+	Parameters:
+	-----------
+		length (int): number of digits (must be positive)
+		allowRepeat (bool): allows repeat digits
+
+	Returns:
+	--------
+		string, error - PIN and the error if the PIN was not generated
+			Note: ErrDigitsExceedsAvailable is returned if the length exceeds the number of digits and repeats are not allowed
+*/
+func (g *Generator) GeneratePIN(length int, allowRepeat bool) (string, error) {
+	if length < 1 {
+		return "", fmt.Errorf("%w: length must be positive", ErrInvalidArgument)
+	}
+	digits := string(distinctChars(g.digits))
+	if !allowRepeat && length > utf8.RuneCountInString(digits) {
+		return "", ErrDigitsExceedsAvailable
+	}
+	if err := g.verifyEntropy(drawEntropy(utf8.RuneCountInString(digits), length, allowRepeat)); err != nil {
+		return "", err
+	}
+	return g.randomStringFrom(digits, length, allowRepeat)
+}
+
+/*
+Function to generate a PIN with its spoken form, e.g. to read it aloud.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		length (int): number of digits
+
+	Returns:
+	--------
+		string, string, error - PIN, its spoken form and the error if the PIN was not generated
+*/
+func (g *Generator) GenerateSpokenPIN(length int) (pin string, spoken string, err error) {
+	if length < 1 {
+		return "", "", fmt.Errorf("%w: length must be positive", ErrInvalidArgument)
+	}
+	if g.digits == "" {
+		return "", "", ErrEmptyPool
+	}
+	pin, err = g.randomString(g.digits, length)
+	if err != nil {
+		return "", "", err
+	}
+	return pin, DigitsToWords(pin), nil
+}
+
+/*
+Function which generates distinct random codes from an alphabet.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		alphabet (string): characters used in the codes
+		count (int): number of codes
+		codeLen (int): number of characters of each code
+
+	Returns:
+	--------
+		[]string, error - codes and the error if the codes were not generated
+*/
+func (g *Generator) distinctCodes(alphabet string, count, codeLen int) ([]string, error) {
+	// Verify if there are enough possible codes
+	if math.Pow(float64(utf8.RuneCountInString(alphabet)), float64(codeLen)) < float64(count) {
+		return nil, ErrCodeSpaceTooSmall
+	}
+
+	codes := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	var attempt int
+	for len(codes) < count {
+		code, err := g.randomString(alphabet, codeLen)
+		if err != nil {
+			return nil, err
+		}
+		// Draw again the codes already given
+		if seen[code] {
+			attempt++
+			g.notifyRetry(attempt, fmt.Sprintf("code %q already generated", code))
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+/*
+Function which returns an insertion position biased toward the middle of a string
+	Method of Generator type
+
+	Parameters:
+	-----------
+		n (int): length of the string
+		bias (float64): strength of the bias (see WithSymbolPlacementBias), 0 for a uniform position
+
+	Returns:
+	--------
+		int, error - position in [0, n] and the error if the position was not drawn
+*/
+func (g *Generator) biasedPosition(n int, bias float64) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	if bias <= 0 {
+		return g.placementInt(n + 1)
+	}
+
+	// Number of averaged positions
+	draws := 1 + int(bias)
+	f, err := g.placementFloat()
+	if err != nil {
+		return 0, err
+	}
+	if f < bias-math.Floor(bias) {
+		draws++
+	}
+
+	// Average the positions
+	sum := 0
+	for i := 0; i < draws; i++ {
+		pos, err := g.placementInt(n + 1)
+		if err != nil {
+			return 0, err
+		}
+		sum += pos
+	}
+	// Round the average up with a probability equal to its fractional part
+	pos := sum / draws
+	r, err := g.placementInt(draws)
+	if err != nil {
+		return 0, err
+	}
+	if r < sum%draws {
+		pos++
+	}
+	return pos, nil
+}
+
+/*
+Function which returns a random position in [0, n)
+	Method of Generator type
+	The position is drawn from the seeded source of WithPlacementSeed if any,
+	from the random source of the generator otherwise.
+
+	Parameters:
+	-----------
+		n (int): upper bound (excluded), must be positive
+
+	Returns:
+	--------
+		int, error - random position and the error if it was not drawn
+*/
+func (g *Generator) placementInt(n int) (int, error) {
+	if g.placement != nil {
+		return g.placement.IntN(n), nil
+	}
+	return g.randomInt(n)
+}
+
+/*
+Function which returns a random float in [0, 1) for the placement of a character
+	Method of Generator type
+
+	Returns:
+	--------
+		float64, error - random float and the error if it was not drawn
+*/
+func (g *Generator) placementFloat() (float64, error) {
+	if g.placement != nil {
+		return g.placement.Float64(), nil
+	}
+	return g.randomFloat()
+}
+
+/*
+Function which randomly return a value from a given string
+	Method of Generator type
+	The value is a whole character, even if it is encoded on several bytes.
+
+	Parameters:
+	-----------
+		str (int): string to use
+
+	Returns:
+	--------
+		string, error - extracted value was inserted and the error if value not inserted
+*/
+func (g *Generator) randomElement(str string) (string, error) {
+	// Draw among the characters if some of them are encoded on several bytes
+	for i := 0; i < len(str); i++ {
+		if str[i] >= utf8.RuneSelf {
+			r, err := g.randomRune([]rune(str))
+			if err != nil {
+				return "", err
+			}
+			return string(r), nil
+		}
+	}
+
+	// Get a random index
+	n, err := g.randomInt(len(str))
+	if err != nil {
+		return "", err
+	}
+	// Directly return the choiced value
+	return str[n : n+1], nil
+}
+
+/*
+Function which returns a copy of the generator safe to use from several goroutines.
+	Method of Generator type
+	crypto/rand is already safe for concurrent use, other sources, the seeded
+	placements and the retry observer are wrapped behind a mutex.
+
+	Returns:
+	--------
+		*Generator - copy of the generator
+*/
+func (g *Generator) concurrent() *Generator {
+	shared := *g
+	if g.reader != rand.Reader {
+		shared.reader = &lockedReader{r: g.reader}
+	}
+	if g.placement != nil {
+		shared.placement = mathrand.New(&lockedSource{r: g.placement})
+	}
+	if observer := g.retryObserver; observer != nil {
+		var mu sync.Mutex
+		shared.retryObserver = func(attempt int, reason string) {
+			mu.Lock()
+			defer mu.Unlock()
+			observer(attempt, reason)
+		}
+	}
+	return &shared
+}
+
+/*
+Function which returns a copy of the generator stopping when the given context is cancelled.
+	Method of Generator type
+
+	Parameters:
+	-----------
+		ctx (context.Context): context cancelling the generation
+
+	Returns:
+	--------
+		*Generator - copy of the generator (the generator itself if the context is never cancelled)
+*/
+func (g *Generator) withContext(ctx context.Context) *Generator {
+	if ctx.Done() == nil {
+		return g
+	}
+	c := *g
+	c.ctx = ctx
+	return &c
+}
+
+/*
+Function which returns the error of the context of the generator, if any.
+	Method of Generator type
+
+	Returns:
+	--------
+		error - error of the context if it was cancelled, nil otherwise
+*/
+func (g *Generator) cancelled() error {
+	if g.ctx == nil {
+		return nil
+	}
+	return g.ctx.Err()
+}
+
+/*
+Function which returns a copy of the generator drawing everything from a stream keyed with the given key.
+	Method of Generator type
+	The placements are drawn from the stream too (a seeded placement source of
+	the generator is not used, it would be shared with the generator), so the
+	passwords of the copy only depend on the key.
+
+	Parameters:
+	-----------
+		key ([]byte): 32-byte key of the ChaCha8 stream
+
+	Returns:
+	--------
+		*Generator - copy of the generator
+*/
+func (g *Generator) derived(key []byte) *Generator {
+	d := *g
+	d.reader = mathrand.NewChaCha8([32]byte(key))
+	d.placement = nil
+	d.scratch = nil
+	return &d
+}
+
+/*
+Function which returns a copy of the generator for generating many passwords in one goroutine.
+	Method of Generator type
+	The copy reads the random bytes by blocks of batchReadSize bytes and
+	reuses the same buffer to assemble the passwords, which saves most of the
+	allocations. It must not be shared between goroutines.
+
+	Parameters:
+	-----------
+		length (int): expected number of characters of the passwords
+
+	Returns:
+	--------
+		*Generator - copy of the generator
+*/
+func (g *Generator) batch(length int) *Generator {
+	b := *g
+	b.reader = bufio.NewReaderSize(g.reader, batchReadSize)
+	b.scratch = make([]rune, 0, max(length, 0))
+	return &b
+}
+
+// lockedReader is a reader serializing the reads of the wrapped reader.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+// Read reads from the wrapped reader while holding the lock.
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
+
+// lockedSource is a source of random numbers serializing the draws of the
+// wrapped generator, e.g. the seeded placements shared by several goroutines.
+type lockedSource struct {
+	mu sync.Mutex
+	r  *mathrand.Rand
+}
+
+// Uint64 draws from the wrapped generator while holding the lock.
+func (l *lockedSource) Uint64() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Uint64()
+}
+
+/*
+Function which generates a cryptographically random salt
+	Parameters:
+	-----------
+		nBytes (int): number of bytes of the salt (must be positive)
+
+	Returns:
+	--------
+		[]byte, error - salt and the error if the salt was not generated
+*/
+func GenerateSalt(nBytes int) ([]byte, error) {
+	if nBytes < 1 {
+		return nil, fmt.Errorf("%w: nBytes must be positive", ErrInvalidArgument)
+	}
+	salt := make([]byte, nBytes)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+/*
+Function which generates a base32 shared secret for TOTP authenticator apps
+	Parameters:
+	-----------
+		nBytes (int): number of random bytes of the secret (20 if not positive)
+
+	Returns:
+	--------
+		string, error - secret (uppercase, no padding) and the error if the secret was not generated
+*/
+func GenerateTOTPSecret(nBytes int) (string, error) {
+	if nBytes <= 0 {
+		nBytes = DefaultTOTPSecretSize
+	}
+	secret, err := GenerateSalt(nBytes)
+	if err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+/*
+Function which generates a passphrase of words drawn uniformly from the word list
+	Method of Generator type
+	The words come from GeneratorInput.Words, or from an embedded list of
+	common English words. A word may appear several times. With
+	WithPassphraseDigit, a digit is appended to the passphrase.
+
+	Parameters:
+	-----------
+		numWords (int): number of words (must be positive)
+		separator (string): string put between the words
+		capitalize (bool): write the first letter of each word in uppercase
+
+	Returns:
+	--------
+		string, error - passphrase and the error if the passphrase was not generated
+*/
+func (g *Generator) GeneratePassphrase(numWords int, separator string, capitalize bool) (string, error) {
+	if numWords < 1 {
+		return "", fmt.Errorf("%w: numWords must be positive", ErrInvalidArgument)
+	}
+
+	words := make([]string, numWords)
+	for i := range words {
+		n, err := g.randomInt(len(g.words))
+		if err != nil {
+			return "", err
+		}
+		words[i] = g.words[n]
+		if capitalize && !g.forceLowercase {
+			first, size := utf8.DecodeRuneInString(words[i])
+			words[i] = string(unicode.ToUpper(first)) + words[i][size:]
+		}
+	}
+	passphrase := strings.Join(words, separator)
+
+	// Append the digit
+	if g.passphraseDigit {
+		digit, err := g.randomElement(g.digits)
+		if err != nil {
+			return "", err
+		}
+		passphrase += digit
+	}
+	return passphrase, nil
+}
+
+/*
+Function which removes the empty and duplicate words of a word list
+	Parameters:
+	-----------
+		words ([]string): words, surrounding spaces being ignored
+
+	Returns:
+	--------
+		[]string - distinct non-empty words, in their first order of appearance
+*/
+func distinctWords(words []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word != "" && !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+/*
+Function which generates a Diceware passphrase
+	Each word is drawn by rolling DicewareDice six-sided dice, the rolls
+	(e.g. "14236") being the keys of the word list.
+
+	Parameters:
+	-----------
+		numWords (int): number of words (must be positive)
+		wordlist (map[string]string): words by roll, e.g. read with ParseDicewareWordlist (the embedded list if nil)
+
+	Returns:
+	--------
+		string, error - words separated by spaces and the error if the passphrase was not generated
+			Note: ErrIncompleteWordlist is returned if a roll has no word
+*/
+func GenerateDiceware(numWords int, wordlist map[string]string) (string, error) {
+	if numWords < 1 {
+		return "", fmt.Errorf("%w: numWords must be positive", ErrInvalidArgument)
+	}
+	if wordlist == nil {
+		wordlist = defaultDiceware
+	}
+	if len(wordlist) != DicewareListSize {
+		return "", fmt.Errorf("%w: %d words instead of %d", ErrIncompleteWordlist, len(wordlist), DicewareListSize)
+	}
+	for i := range DicewareListSize {
+		if _, ok := wordlist[diceRoll(i)]; !ok {
+			return "", fmt.Errorf("%w: no word for roll %s", ErrIncompleteWordlist, diceRoll(i))
+		}
+	}
+
+	g := NewGenerator(nil)
+	words := make([]string, numWords)
+	for i := range words {
+		// Roll the dice
+		roll := make([]byte, DicewareDice)
+		for j := range roll {
+			n, err := g.randomInt(6)
+			if err != nil {
+				return "", err
+			}
+			roll[j] = byte('1' + n)
+		}
+		words[i] = wordlist[string(roll)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+/*
+Function which reads a Diceware word list
+	Each non-empty line contains a roll and its word separated by spaces or a
+	tab, which is the format of the published lists (e.g. the EFF large word list).
+
+	Parameters:
+	-----------
+		r (io.Reader): content of the word list
+
+	Returns:
+	--------
+		map[string]string, error - words by roll and the error if the content is invalid
+*/
+func ParseDicewareWordlist(r io.Reader) (map[string]string, error) {
+	wordlist := make(map[string]string, DicewareListSize)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d: expected a roll and a word", ErrInvalidWordlist, line)
+		}
+		wordlist[fields[0]] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return wordlist, nil
+}
+
+/*
+Function which returns the Diceware word list embedded in the package
+	The list has a word of 3 to 9 lowercase letters for each of the
+	DicewareListSize rolls, from "11111" to "66666".
+
+	Returns:
+	--------
+		map[string]string - copy of the words by roll
+*/
+func DefaultDicewareWordlist() map[string]string {
+	return maps.Clone(defaultDiceware)
+}
+
+/*
+Function which reads a Diceware word list known to be valid, e.g. an embedded one
+	Parameters:
+	-----------
+		content (string): content of the word list
+
+	Returns:
+	--------
+		map[string]string - words by roll
+			Note: it panics if the content is invalid
+*/
+func mustParseDicewareWordlist(content string) map[string]string {
+	wordlist, err := ParseDicewareWordlist(strings.NewReader(content))
+	if err != nil {
+		panic("passwordgenerator: embedded Diceware word list: " + err.Error())
+	}
+	return wordlist
+}
+
+/*
+Function which returns the Diceware roll of the given index
+	Parameters:
+	-----------
+		i (int): index between 0 and DicewareListSize-1
+
+	Returns:
+	--------
+		string - roll, from "11111" to "66666"
+*/
+func diceRoll(i int) string {
+	roll := make([]byte, DicewareDice)
+	for j := DicewareDice - 1; j >= 0; j-- {
+		roll[j] = byte('1' + i%6)
+		i /= 6
+	}
+	return string(roll)
+}
+
+/*
+Function which generates a random number written in the given base, e.g. for base 36 codes
+	Parameters:
+	-----------
+		base (int): base between 2 and 36 (digits 0-9 then a-z)
+		length (int): number of digits (at least 1)
+
+	Returns:
+	--------
+		string, error - random digits and the error if they were not generated
+*/
+func GenerateBaseN(base, length int) (string, error) {
+	if base < 2 || base > len(radixDigits) {
+		return "", fmt.Errorf("%w: base must be between 2 and %d", ErrInvalidArgument, len(radixDigits))
+	}
+	if length < 1 {
+		return "", fmt.Errorf("%w: length must be positive", ErrInvalidArgument)
+	}
+	return NewGenerator(nil).randomString(radixDigits[:base], length)
+}
+
+/*
+Function which generates the shortest password over an alphabet reaching the given entropy
+	The length is the smallest one for which the number of possible passwords
+	(made of the distinct characters of the alphabet) is at least 2^bits.
+
+	Parameters:
+	-----------
+		alphabet (string): characters to use (non-empty)
+		bits (float64): minimal entropy in bits (positive)
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - password and the error if the password was not generated
+			Note: ErrEntropyUnreachable is returned if even the longest password is too weak
+*/
+func GenerateAlphabetMinEntropy(alphabet string, bits float64, allowRepeat bool) (string, error) {
+	if alphabet == "" || !(bits > 0) {
+		return "", fmt.Errorf("%w: alphabet must not be empty and bits must be positive", ErrInvalidArgument)
+	}
+	chars := distinctChars(alphabet)
+	n := len(chars)
+
+	// Find the shortest length reaching the entropy
+	var length int
+	if allowRepeat {
+		if n < 2 {
+			return "", ErrEntropyUnreachable
+		}
+		length = int(math.Ceil(bits / math.Log2(float64(n))))
+	} else {
+		for length = 1; log2Factorial(n)-log2Factorial(n-length) < bits; length++ {
+			if length == n {
+				return "", ErrEntropyUnreachable
+			}
+		}
+	}
+
+	return NewGenerator(nil).randomStringFrom(string(chars), length, allowRepeat)
+}
+
+/*
+Function which spells the digits of a string in English
+	The characters other than the digits 0 to 9 are ignored.
+
+	Parameters:
+	-----------
+		s (string): string containing the digits
+
+	Returns:
+	--------
+		string - words of the digits separated by spaces (e.g. "four two" for "42")
+*/
+func DigitsToWords(s string) string {
+	words := make([]string, 0, len(s))
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			words = append(words, digitWords[r-'0'])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+/*
+Function which masks a password to display it, e.g. "Ab3●●●●●●f7"
+	Each character except the first visiblePrefix and the last visibleSuffix
+	ones is replaced by maskRune, so the masked form has as many characters as
+	the password. If the visible characters would reveal the whole password,
+	every character is masked.
+
+	Parameters:
+	-----------
+		password (string): password to mask
+		visiblePrefix (int): number of characters shown at the start (none if not positive)
+		visibleSuffix (int): number of characters shown at the end (none if not positive)
+		maskRune (rune): character replacing the hidden characters
+
+	Returns:
+	--------
+		string - masked password
+*/
+func Mask(password string, visiblePrefix, visibleSuffix int, maskRune rune) string {
+	runes := []rune(password)
+	visiblePrefix, visibleSuffix = max(visiblePrefix, 0), max(visibleSuffix, 0)
+	if visiblePrefix+visibleSuffix >= len(runes) {
+		visiblePrefix, visibleSuffix = 0, 0
+	}
+	for i := visiblePrefix; i < len(runes)-visibleSuffix; i++ {
+		runes[i] = maskRune
+	}
+	return string(runes)
+}
+
+/*
+Function which runs checks on a password
+	Parameters:
+	-----------
+		password (string): password to check
+		checks ([]check): checks to run
+
+	Returns:
+	--------
+		string - reason of the first failed check, empty if all the checks passed
+*/
+func runChecks(password string, checks []check) string {
+	for _, c := range checks {
+		if reason := c(password); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+/*
+Function which creates a check rejecting the passwords containing one of the given substrings
+	Parameters:
+	-----------
+		substrings ([]string): forbidden substrings, compared without case
+		minLen (int): minimal number of characters of a substring to be considered
+
+	Returns:
+	--------
+		check - check of the forbidden substrings
+*/
+func avoidSubstrings(substrings []string, minLen int) check {
+	forbidden := make([]string, 0, len(substrings))
+	for _, sub := range substrings {
+		if utf8.RuneCountInString(sub) >= minLen {
+			forbidden = append(forbidden, strings.ToLower(sub))
+		}
+	}
+	return func(password string) string {
+		lower := strings.ToLower(password)
+		for _, sub := range forbidden {
+			if strings.Contains(lower, sub) {
+				return fmt.Sprintf("contains %q", sub)
+			}
+		}
+		return ""
+	}
+}
+
+/*
+Function which creates a check rejecting the passwords containing a sequence
+	A sequence is made of characters following each other (e.g. abc), in
+	reverse order (e.g. 321) or identical (e.g. aaa), the case being ignored.
+
+	Parameters:
+	-----------
+		n (int): number of characters from which a sequence is rejected
+
+	Returns:
+	--------
+		check - check of the sequences
+*/
+func avoidSequences(n int) check {
+	return func(password string) string {
+		chars := []rune(strings.ToLower(password))
+		up, down, same := 1, 1, 1
+		for i := 1; i < len(chars); i++ {
+			// Extend or restart the runs ending with the character
+			step := chars[i] - chars[i-1]
+			up, down, same = runStep(up, step == 1), runStep(down, step == -1), runStep(same, step == 0)
+			if run := max(up, down, same); run >= n {
+				return fmt.Sprintf("sequence %q", string(chars[i+1-run:i+1]))
+			}
+		}
+		return ""
+	}
+}
+
+/*
+Function which returns the length of a run after one more character
+	Parameters:
+	-----------
+		run (int): length of the run before the character
+		extends (bool): the character continues the run
+
+	Returns:
+	--------
+		int - length of the run, 1 if it restarts with the character
+*/
+func runStep(run int, extends bool) int {
+	if extends {
+		return run + 1
+	}
+	return 1
+}
+
+/*
+Function which creates a check rejecting the passwords containing a walk on a QWERTY keyboard
+	Parameters:
+	-----------
+		maxRun (int): maximal number of characters of a walk
+
+	Returns:
+	--------
+		check - check of the keyboard walks
+*/
+func avoidKeyboardWalks(maxRun int) check {
+	return func(password string) string {
+		chars := []rune(password)
+		run := 1
+		for i := 1; i < len(chars); i++ {
+			a, okA := qwertyKeys[chars[i-1]]
+			b, okB := qwertyKeys[chars[i]]
+			dr, dc := a[0]-b[0], a[1]-b[1]
+			if okA && okB && a != b && dr >= -1 && dr <= 1 && dc >= -1 && dc <= 1 {
+				run++
+			} else {
+				run = 1
+			}
+			if run > maxRun {
+				return fmt.Sprintf("keyboard walk %q", string(chars[i-run+1:i+1]))
+			}
+		}
+		return ""
+	}
+}
+
+/*
+Function which creates a check rejecting the passwords containing the letters of one of the given words
+	Parameters:
+	-----------
+		words ([]string): banned words, compared without case
+
+	Returns:
+	--------
+		check - check of the anagrams
+*/
+func avoidAnagrams(words []string) check {
+	var banned []string
+	var counts []map[rune]int
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		count := make(map[rune]int)
+		for _, r := range strings.ToLower(word) {
+			count[r]++
+		}
+		banned = append(banned, word)
+		counts = append(counts, count)
+	}
+	return func(password string) string {
+		available := make(map[rune]int)
+		for _, r := range strings.ToLower(password) {
+			available[r]++
+		}
+		for i, count := range counts {
+			contained := true
+			for r, n := range count {
+				if available[r] < n {
+					contained = false
+					break
+				}
+			}
+			if contained {
+				return fmt.Sprintf("contains an anagram of %q", banned[i])
+			}
+		}
+		return ""
+	}
+}
+
+/*
+Function which computes the length of the run of the same character going through the given position
+	Parameters:
+	-----------
+		chars ([]rune): characters to inspect
+		pos (int): position of the character
+
+	Returns:
+	--------
+		int - number of consecutive repetitions of the character around pos
+*/
+func runAt(chars []rune, pos int) int {
+	start, end := pos, pos+1
+	for start > 0 && chars[start-1] == chars[pos] {
+		start--
+	}
+	for end < len(chars) && chars[end] == chars[pos] {
+		end++
+	}
+	return end - start
+}
+
+/*
+Function which computes the length of the longest run of the same character
+	Parameters:
+	-----------
+		str (string): string to inspect
+
+	Returns:
+	--------
+		int - length of the longest run
+*/
+func longestRun(str string) int {
+	longest, run := 0, 0
+	var previous rune
+	for i, r := range []rune(str) {
+		if i > 0 && r == previous {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		previous = r
+	}
+	return longest
+}
+
+/*
+Function which lists the distinct characters of a string
+	Parameters:
+	-----------
+		str (string): string to inspect
+
+	Returns:
+	--------
+		[]rune - distinct characters in order of first appearance
+*/
+func distinctChars(str string) []rune {
+	var chars []rune
+	seen := make(map[rune]bool)
+	for _, r := range str {
+		if !seen[r] {
+			seen[r] = true
+			chars = append(chars, r)
+		}
+	}
+	return chars
+}
+
+/*
+Function which tells if a character is an ASCII digit
+	Parameters:
+	-----------
+		r (rune): character to test
+
+	Returns:
+	--------
+		bool - true if the character is between 0 and 9
+*/
+func isASCIIDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+/*
+Function which counts the distinct characters of a string
+	Parameters:
+	-----------
+		str (string): string to inspect
+
+	Returns:
+	--------
+		int - number of distinct characters
+*/
+func distinctCount(str string) int {
+	// Mark the ASCII characters in an array, the others in a map
+	var ascii [utf8.RuneSelf]bool
+	var seen map[rune]bool
+	n := 0
+	for _, r := range str {
+		if r < utf8.RuneSelf {
+			if !ascii[r] {
+				ascii[r] = true
+				n++
+			}
+			continue
+		}
+		if seen == nil {
+			seen = make(map[rune]bool)
+		}
+		if !seen[r] {
+			seen[r] = true
+			n++
+		}
+	}
+	return n
+}
+
+/*
+Function which keeps the characters of a pool satisfying the given predicate
+	Parameters:
+	-----------
+		pool (string): list of characters
+		keep (func(rune) bool): predicate of the characters to keep
+
+	Returns:
+	--------
+		string - filtered list of characters
+*/
+func filterPool(pool string, keep func(rune) bool) string {
+	return strings.Map(func(r rune) rune {
+		if keep(r) {
+			return r
+		}
+		return -1
+	}, pool)
+}
+
+/*
+Function which returns a random integer in [0, n)
+	Method of Generator type
+
+	Parameters:
+	-----------
+		n (int): upper bound (excluded), must be positive
+
+	Returns:
+	--------
+		int, error - random integer and the error if it was not drawn
+*/
+func (g *Generator) randomInt(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: cannot draw a number below %d", ErrInvalidArgument, n)
+	}
+
+	// Read the bytes one by one for the batch copies, which avoids the
+	// allocations of rand.Int while drawing the same values
+	if br, ok := g.reader.(io.ByteReader); ok {
+		bitLen := bits.Len64(uint64(n - 1))
+		if bitLen == 0 {
+			return 0, nil
+		}
+		k := (bitLen + 7) / 8
+		mask := byte(1<<(bitLen-(k-1)*8) - 1)
+		for {
+			var v uint64
+			for i := 0; i < k; i++ {
+				c, err := br.ReadByte()
+				if err != nil {
+					return 0, err
+				}
+				if i == 0 {
+					c &= mask
+				}
+				v = v<<8 | uint64(c)
+			}
+			if v < uint64(n) {
+				return int(v), nil
+			}
+		}
+	}
+
+	v, err := rand.Int(g.reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+/*
+Function which returns a random float in [0, 1)
+	Method of Generator type
+
+	Returns:
+	--------
+		float64, error - random float and the error if it was not drawn
+*/
+func (g *Generator) randomFloat() (float64, error) {
+	// Keep the 53 high bits of a random uint64, the precision of a float64
+	var b [8]byte
+	if _, err := io.ReadFull(g.reader, b[:]); err != nil {
+		return 0, err
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53), nil
+}
+
+/*
+Function which builds a random string whose characters are drawn from an alphabet
+	Method of Generator type
+
+	Parameters:
+	-----------
+		alphabet (string): characters to use
+		length (int): number of characters
+
+	Returns:
+	--------
+		string, error - random string and the error if it was not generated
+*/
+func (g *Generator) randomString(alphabet string, length int) (string, error) {
+	chars := []rune(alphabet)
+	b := make([]rune, length)
+	for i := range b {
+		r, err := g.randomRune(chars)
+		if err != nil {
+			return "", err
+		}
+		b[i] = r
+	}
+	return string(b), nil
+}
+
+/*
+Function which builds a random string from an alphabet, with or without repeated characters
+	Method of Generator type
+
+	Parameters:
+	-----------
+		alphabet (string): characters to use
+		length (int): number of characters (must be positive)
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		string, error - random string and the error if it was not generated
+*/
+func (g *Generator) randomStringFrom(alphabet string, length int, allowRepeat bool) (string, error) {
+	if length < 1 {
+		return "", fmt.Errorf("%w: length must be positive", ErrInvalidArgument)
+	}
+	if alphabet == "" {
+		return "", ErrEmptyPool
+	}
+	if allowRepeat {
+		return g.randomString(alphabet, length)
+	}
+
+	// Draw the characters from a shrinking pool
+	pool := []rune(alphabet)
+	if length > len(pool) {
+		return "", ErrLengthExceedsAvailable
+	}
+	b := make([]rune, length)
+	for i := range b {
+		n, err := g.randomInt(len(pool))
+		if err != nil {
+			return "", err
+		}
+		b[i] = pool[n]
+		pool[n] = pool[len(pool)-1]
+		pool = pool[:len(pool)-1]
+	}
+	return string(b), nil
+}
+
+/*
+Function which randomly shuffles n elements with the Fisher-Yates algorithm
+	Method of Generator type
+
+	Parameters:
+	-----------
+		n (int): number of elements
+		swap (func(int, int)): function swapping the elements of indexes i and j
+
+	Returns:
+	--------
+		error - the error if the elements were not shuffled
+*/
+func (g *Generator) shuffle(n int, swap func(i, j int)) error {
+	for i := n - 1; i > 0; i-- {
+		j, err := g.randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		swap(i, j)
+	}
+	return nil
+}
+
+/*
+Function which randomly returns a character from a given list
+	Method of Generator type
+
+	Parameters:
+	-----------
+		chars ([]rune): non-empty list of characters
+
+	Returns:
+	--------
+		rune, error - extracted character and the error if it was not drawn
+*/
+func (g *Generator) randomRune(chars []rune) (rune, error) {
+	n, err := g.randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[n], nil
+}
+
+/*
+Function which verifies that an estimated entropy reaches the minimal entropy of the generator
+	Method of Generator type
+
+	Parameters:
+	-----------
+		bits (float64): estimated entropy of the requested password in bits
+
+	Returns:
+	--------
+		error - ErrInsufficientEntropy if the entropy is below the minimum, nil otherwise
+*/
+func (g *Generator) verifyEntropy(bits float64) error {
+	if g.minEntropy > 0 && bits < g.minEntropy {
+		return ErrInsufficientEntropy
+	}
+	return nil
+}
+
+/*
+Function which computes the entropy of n characters drawn uniformly from a set
+	Parameters:
+	-----------
+		poolSize (int): number of distinct characters of the set
+		n (int): number of drawn characters (at most poolSize if repeats are not allowed)
+		allowRepeat (bool): allows repeat characters
+
+	Returns:
+	--------
+		float64 - entropy in bits
+*/
+func drawEntropy(poolSize, n int, allowRepeat bool) float64 {
+	if n == 0 {
+		return 0
+	}
+	if allowRepeat {
+		return float64(n) * math.Log2(float64(poolSize))
+	}
+	return log2Factorial(poolSize) - log2Factorial(poolSize-n)
+}
+
+/*
+Function which computes the base 2 logarithm of n!
+	Parameters:
+	-----------
+		n (int): positive number
+
+	Returns:
+	--------
+		float64 - log2(n!)
+*/
+func log2Factorial(n int) float64 {
+	lg, _ := math.Lgamma(float64(n) + 1)
+	return lg / math.Ln2
+}
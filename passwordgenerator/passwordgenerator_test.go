@@ -0,0 +1,3194 @@
+package passwordgenerator
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	mathrand "math/rand/v2"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestRandomFloat(t *testing.T) {
+	g := NewGenerator(nil)
+	for range 1000 {
+		f, err := g.randomFloat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f < 0 || f >= 1 {
+			t.Fatalf("randomFloat() = %v, want a value in [0, 1)", f)
+		}
+	}
+}
+
+// expandB is a Normalizer giving two runes for the letter b, so the
+// normalized passwords do not all have the same number of runes.
+type expandB struct{}
+
+func (expandB) String(s string) string { return strings.ReplaceAll(s, "b", "bb") }
+
+func TestGeneratePairDifferentLengths(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab", UpperLetters: "AB", Digits: "01", Symbols: "!?"}, WithNormalization(expandB{}))
+	params := GenerateConfig{AllowRepeat: true}
+	for range 100 {
+		a, b, err := g.GeneratePair(4, 4, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a == "" || b == "" {
+			t.Fatalf("GeneratePair() = %q, %q, want two passwords", a, b)
+		}
+	}
+}
+
+// countClasses counts the digits and symbols of a password.
+func countClasses(g *Generator, password string) (digits, symbols int) {
+	for _, r := range password {
+		switch {
+		case strings.ContainsRune(g.digits, r):
+			digits++
+		case strings.ContainsRune(g.symbols, r):
+			symbols++
+		}
+	}
+	return digits, symbols
+}
+
+// Run with -race: the workers share the seeded placements, the random
+// source and the retry observer of the generator.
+func TestGenerateManyParallel(t *testing.T) {
+	retries := 0
+	input := &GeneratorInput{Reader: mathrand.NewChaCha8([32]byte{1})}
+	for name, g := range map[string]*Generator{
+		"crypto/rand": NewGenerator(nil),
+		"seeded":      NewGenerator(input, WithPlacementSeed(42), WithRetryObserver(func(int, string) { retries++ })),
+	} {
+		t.Run(name, func(t *testing.T) {
+			params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 2, AllowUppercase: true}
+			passwords, err := g.GenerateManyParallel(500, 8, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(passwords) != 500 {
+				t.Fatalf("got %d passwords, want 500", len(passwords))
+			}
+			for _, pwd := range passwords {
+				digits, symbols := countClasses(g, pwd)
+				if utf8.RuneCountInString(pwd) != 16 || digits != 3 || symbols != 2 {
+					t.Fatalf("password %q does not follow %+v", pwd, params)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateManyParallel(b *testing.B) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	const count = 1000
+	b.Run("serial", func(b *testing.B) {
+		for b.Loop() {
+			for range count {
+				if _, err := g.GenerateWithConfig(params); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := g.GenerateManyParallel(count, 0, params); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestWithBlocklistShortSubstrings(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "abc"}, WithBlocklist([]string{"AB", "c"}))
+	passwords, err := g.GenerateN(7, 6, 0, 0, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pwd := range passwords {
+		if strings.Contains(pwd, "ab") || strings.Contains(pwd, "c") {
+			t.Fatalf("password %q contains a banned substring", pwd)
+		}
+	}
+}
+
+func TestDefaultDicewareWordlist(t *testing.T) {
+	wordlist := DefaultDicewareWordlist()
+	if len(wordlist) != DicewareListSize {
+		t.Fatalf("got %d words, want %d", len(wordlist), DicewareListSize)
+	}
+	seen := make(map[string]bool, len(wordlist))
+	for roll, word := range wordlist {
+		if len(roll) != DicewareDice || strings.Trim(roll, "123456") != "" {
+			t.Errorf("roll %q is not made of %d dice", roll, DicewareDice)
+		}
+		if seen[word] {
+			t.Errorf("word %q appears several times", word)
+		}
+		seen[word] = true
+	}
+	for i := range DicewareListSize {
+		if _, ok := wordlist[diceRoll(i)]; !ok {
+			t.Errorf("no word for roll %s", diceRoll(i))
+		}
+	}
+}
+
+func TestGenerateDicewareDefault(t *testing.T) {
+	passphrase, err := GenerateDiceware(6, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := strings.Fields(passphrase)
+	if len(words) != 6 {
+		t.Fatalf("GenerateDiceware(6, nil) = %q, want 6 words", passphrase)
+	}
+	known := make(map[string]bool, DicewareListSize)
+	for _, word := range DefaultDicewareWordlist() {
+		known[word] = true
+	}
+	for _, word := range words {
+		if !known[word] {
+			t.Errorf("word %q is not in the embedded list", word)
+		}
+	}
+}
+
+// chiSquare returns the chi-square statistic of the observed counts against
+// the same expected count for each of them.
+func chiSquare(counts []int, expected float64) float64 {
+	stat := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		stat += d * d / expected
+	}
+	return stat
+}
+
+func TestPlacementUniform(t *testing.T) {
+	const runs = 50000
+	g := NewSeededGenerator(nil, 264)
+	params := GenerateConfig{Length: 8, NumDigits: 2, NumSymbols: 1, AllowRepeat: true}
+
+	// Count the digits and the symbols at each position, and the arrangements
+	// of the classes (8!/(5!2!1!) = 168 equally likely ones)
+	digitsAt := make([]int, params.Length)
+	symbolsAt := make([]int, params.Length)
+	arrangements := make(map[string]int)
+	for range runs {
+		pwd, err := g.GenerateWithConfig(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pattern := []byte(pwd)
+		for i, r := range pwd {
+			switch {
+			case strings.ContainsRune(g.digits, r):
+				digitsAt[i]++
+				pattern[i] = 'D'
+			case strings.ContainsRune(g.symbols, r):
+				symbolsAt[i]++
+				pattern[i] = 'S'
+			default:
+				pattern[i] = 'L'
+			}
+		}
+		arrangements[string(pattern)]++
+	}
+
+	// Critical values of the chi-square distribution for p = 0.001
+	if stat := chiSquare(digitsAt, runs*2.0/8); stat > 24.32 {
+		t.Errorf("digits not uniform over the positions: chi2 = %.1f, counts %v", stat, digitsAt)
+	}
+	if stat := chiSquare(symbolsAt, runs/8.0); stat > 24.32 {
+		t.Errorf("symbols not uniform over the positions: chi2 = %.1f, counts %v", stat, symbolsAt)
+	}
+	if len(arrangements) != 168 {
+		t.Fatalf("got %d arrangements of the classes, want 168", len(arrangements))
+	}
+	counts := make([]int, 0, len(arrangements))
+	for _, c := range arrangements {
+		counts = append(counts, c)
+	}
+	if stat := chiSquare(counts, runs/168.0); stat > 229.3 {
+		t.Errorf("arrangements not uniform: chi2 = %.1f", stat)
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	g := NewGenerator(nil)
+	for _, length := range []int{16, 256, 4096} {
+		params := GenerateConfig{Length: length, NumDigits: length / 4, NumSymbols: length / 8, AllowUppercase: true, AllowRepeat: true}
+		b.Run(strconv.Itoa(length), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := g.GenerateWithConfig(params); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWithMinimumsLettersExceedAvailable(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab", UpperLetters: "ABCDEF"})
+	for _, tt := range []struct{ minLower, minUpper int }{{3, 0}, {0, 7}, {3, 1}} {
+		_, err := g.GenerateWithMinimums(10, tt.minLower, tt.minUpper, 0, 0, false)
+		if !errors.Is(err, ErrLettersExceedsAvailable) {
+			t.Errorf("GenerateWithMinimums(10, %d, %d, 0, 0, false) error = %v, want %v", tt.minLower, tt.minUpper, err, ErrLettersExceedsAvailable)
+		}
+	}
+}
+
+func TestGenerateWithinAllowedEmptyClass(t *testing.T) {
+	g := NewGenerator(nil)
+	_, err := g.GenerateWithinAllowed("abc123", 8, 2, 1, false, true)
+	if !errors.Is(err, ErrEmptyPool) || !strings.HasSuffix(err.Error(), ": symbols") {
+		t.Fatalf("GenerateWithinAllowed() error = %v, want %v naming the symbols", err, ErrEmptyPool)
+	}
+}
+
+func TestGenerateWithEntropy(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, params := range []GenerateConfig{
+		{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true},
+		{Length: 16, NumDigits: 4, NumSymbols: 3, AllowUppercase: false, AllowRepeat: false},
+		{Length: 8},
+	} {
+		password, bits, err := g.GenerateWithEntropy(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if password == "" {
+			t.Errorf("GenerateWithEntropy(%+v) returned no password", params)
+		}
+		if want := g.Entropy(params); bits != want {
+			t.Errorf("GenerateWithEntropy(%+v) bits = %v, want Entropy() = %v", params, bits, want)
+		}
+	}
+}
+
+// Many constraints on a small pool, which rejection sampling alone rarely
+// satisfies: the passwords are then built directly.
+func TestGenerateConstrainedStress(t *testing.T) {
+	input := &GeneratorInput{
+		LowerLetters:    "abcdef",
+		UpperLetters:    "ABC",
+		Digits:          "0369",
+		Symbols:         "!?",
+		Blocklist:       []string{"ab", "fA"},
+		RejectSequences: true,
+	}
+	g := NewGenerator(input, WithMaxRunLength(1))
+	params := GenerateConfig{Length: 14, NumDigits: 3, NumSymbols: 2, AllowUppercase: true}
+	for range 200 {
+		pwd, err := g.GenerateWithConfig(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digits, symbols := countClasses(g, pwd)
+		if utf8.RuneCountInString(pwd) != 14 || digits != 3 || symbols != 2 {
+			t.Fatalf("password %q does not follow %+v", pwd, params)
+		}
+		if distinctCount(pwd) != 14 {
+			t.Fatalf("password %q repeats a character", pwd)
+		}
+		if reason := runChecks(pwd, g.checks); reason != "" {
+			t.Fatalf("password %q is rejected: %s", pwd, reason)
+		}
+	}
+}
+
+func BenchmarkGenerateMany(b *testing.B) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	for _, count := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := g.GenerateMany(count, params); err != nil {
+					b.Fatal(err)
+				}
+			}
+			// Allocations per password, which should not grow with the batch
+			b.ReportMetric(float64(testing.AllocsPerRun(1, func() { _, _ = g.GenerateMany(count, params) }))/float64(count), "allocs/password")
+		})
+	}
+}
+
+func TestDerivePassword(t *testing.T) {
+	g := NewGenerator(nil, WithPlacementSeed(5))
+	seed := []byte("master seed")
+	params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 3, AllowUppercase: true}
+	first, err := g.DerivePassword(seed, 1, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		again, err := g.DerivePassword(seed, 1, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again != first {
+			t.Fatalf("DerivePassword(seed, 1) = %q then %q, want the same password", first, again)
+		}
+	}
+	if other, err := NewGenerator(nil).DerivePassword(seed, 1, params); err != nil || other != first {
+		t.Fatalf("DerivePassword(seed, 1) without a placement seed = %q, %v, want %q", other, err, first)
+	}
+
+	seen := map[string]uint32{first: 1}
+	for index := uint32(2); index <= 100; index++ {
+		pwd, err := g.DerivePassword(seed, index, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, ok := seen[pwd]; ok {
+			t.Fatalf("indexes %d and %d both give %q", prev, index, pwd)
+		}
+		seen[pwd] = index
+	}
+	if _, err := g.DerivePassword(nil, 1, params); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("DerivePassword(nil) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestMutateBase(t *testing.T) {
+	g := NewGenerator(nil, WithPlacementSeed(5))
+	first, err := g.MutateBase("correct horse", "example.com", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first, "ceoxrarmepclte .hcoormse") || utf8.RuneCountInString(first) != 28 {
+		t.Fatalf("MutateBase() = %q, want the interleaved base and tag followed by 4 digits", first)
+	}
+	for range 10 {
+		for _, h := range []*Generator{g, NewGenerator(nil)} {
+			if again, err := h.MutateBase("correct horse", "example.com", 4); err != nil || again != first {
+				t.Fatalf("MutateBase() = %q, %v, want %q again", again, err, first)
+			}
+		}
+	}
+	other, err := g.MutateBase("correct horse", "example.org", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Errorf("MutateBase() gives %q for two sites", other)
+	}
+
+	// The digits depend on the base and on the site tag
+	suffixes := make(map[string]bool)
+	for _, args := range [][2]string{{"correct horse", "a.com"}, {"correct horse", "b.com"}, {"correct horsf", "a.com"}, {"correct horsf", "b.com"}} {
+		mutated, err := g.MutateBase(args[0], args[1], 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digits := mutated[len(mutated)-8:]
+		if strings.Trim(digits, g.digits) != "" {
+			t.Fatalf("MutateBase(%q, %q, 8) = %q, want 8 digits at the end", args[0], args[1], mutated)
+		}
+		suffixes[digits] = true
+	}
+	if len(suffixes) != 4 {
+		t.Errorf("MutateBase() gives %d different digits for 4 bases and sites", len(suffixes))
+	}
+	if mutated, err := g.MutateBase("abc", "xy", 0); err != nil || mutated != "axbyc" {
+		t.Errorf("MutateBase(%q, %q, 0) = %q, %v, want %q", "abc", "xy", mutated, err, "axbyc")
+	}
+	for _, args := range [][2]string{{"", "a.com"}, {"base", ""}} {
+		if _, err := g.MutateBase(args[0], args[1], 4); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("MutateBase(%q, %q) error = %v, want %v", args[0], args[1], err, ErrInvalidArgument)
+		}
+	}
+	if _, err := g.MutateBase("base", "a.com", -1); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("MutateBase(-1 digits) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestNewGeneratorWithOptions(t *testing.T) {
+	g := NewGeneratorWithOptions(WithLowerLetters("abc"), WithUpperLetters("XYZ"), WithDigits("12"), WithSymbols("#"), WithReader(nil))
+	if g.lowerLetters != "abc" || g.upperLetters != "XYZ" || g.digits != "12" || g.symbols != "#" {
+		t.Fatalf("sets = %q, %q, %q, %q, want the sets of the options", g.lowerLetters, g.upperLetters, g.digits, g.symbols)
+	}
+	pwd, err := g.Generate(8, 2, 1, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Trim(pwd, "abcXYZ12#") != "" {
+		t.Fatalf("password %q uses characters outside the sets", pwd)
+	}
+
+	// The empty sets keep their default value
+	g = NewGeneratorWithOptions(WithLowerLetters(""), WithDigits(""))
+	if g.lowerLetters != LowerLetters || g.digits != Digits {
+		t.Errorf("sets = %q, %q, want the defaults", g.lowerLetters, g.digits)
+	}
+
+	seeded := func() *Generator { return NewGeneratorWithOptions(WithReader(mathrand.NewChaCha8([32]byte{7}))) }
+	a, err := seeded().Generate(16, 3, 3, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := seeded().Generate(16, 3, 3, true, false); err != nil || b != a {
+		t.Errorf("two generators reading the same stream give %q and %q (%v)", a, b, err)
+	}
+}
+
+func TestGenerateGrid(t *testing.T) {
+	g := NewGenerator(nil)
+	grid, err := g.GenerateGrid(5, 4, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grid) != 5 {
+		t.Fatalf("got %d rows, want 5", len(grid))
+	}
+	seen := make(map[string]bool)
+	for _, row := range grid {
+		if len(row) != 4 {
+			t.Fatalf("row %q has %d codes, want 4", row, len(row))
+		}
+		for _, code := range row {
+			if utf8.RuneCountInString(code) != 6 || strings.Trim(code, SafeAlphabet) != "" {
+				t.Errorf("code %q is not made of 6 characters of SafeAlphabet", code)
+			}
+			if seen[code] {
+				t.Errorf("code %q appears several times", code)
+			}
+			seen[code] = true
+		}
+	}
+
+	// Appending to a row leaves the next one unchanged
+	next := grid[1][0]
+	_ = append(grid[0], "XXX")
+	if grid[1][0] != next {
+		t.Errorf("appending to the first row changed the second one: %q, want %q", grid[1][0], next)
+	}
+
+	if _, err := g.GenerateGrid(3, 3, 1); err != nil {
+		t.Errorf("GenerateGrid(3, 3, 1) error = %v", err)
+	}
+	if _, err := g.GenerateGrid(10, 10, 1); !errors.Is(err, ErrCodeSpaceTooSmall) {
+		t.Errorf("GenerateGrid(10, 10, 1) error = %v, want %v", err, ErrCodeSpaceTooSmall)
+	}
+	if _, err := g.GenerateGrid(0, 4, 6); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateGrid(0, 4, 6) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestWithMinEntropy(t *testing.T) {
+	g := NewGenerator(nil, WithMinEntropy(60))
+	generators := []struct {
+		name           string
+		weak, adequate func() error
+	}{
+		{"Generate", func() error {
+			_, err := g.Generate(8, 2, 1, true, true)
+			return err
+		}, func() error {
+			_, err := g.Generate(16, 2, 1, true, true)
+			return err
+		}},
+		{"GenerateWithMinimums", func() error {
+			_, err := g.GenerateWithMinimums(8, 1, 1, 1, 1, true)
+			return err
+		}, func() error {
+			_, err := g.GenerateWithMinimums(16, 1, 1, 1, 1, true)
+			return err
+		}},
+		{"GenerateFromPattern", func() error {
+			_, err := g.GenerateFromPattern("UDDD-LLLL")
+			return err
+		}, func() error {
+			_, err := g.GenerateFromPattern("ULLL-LLLL-DDDD-SSSS")
+			return err
+		}},
+		{"GenerateSplit", func() error {
+			_, _, err := g.GenerateSplit(8, 2)
+			return err
+		}, func() error {
+			_, _, err := g.GenerateSplit(12, 2)
+			return err
+		}},
+		{"GenerateCompliant", func() error {
+			_, _, err := g.GenerateCompliant("nist-800-63b", 8)
+			return err
+		}, func() error {
+			_, _, err := g.GenerateCompliant("pci-dss-4.0", 12)
+			return err
+		}},
+		{"GeneratePIN", func() error {
+			_, err := g.GeneratePIN(10, true)
+			return err
+		}, func() error {
+			_, err := g.GeneratePIN(20, true)
+			return err
+		}},
+	}
+	for _, gen := range generators {
+		if err := gen.weak(); !errors.Is(err, ErrInsufficientEntropy) {
+			t.Errorf("%s(weak) error = %v, want %v", gen.name, err, ErrInsufficientEntropy)
+		}
+		if err := gen.adequate(); err != nil {
+			t.Errorf("%s(adequate) error = %v", gen.name, err)
+		}
+	}
+
+	// The default floor accepts any password
+	if _, err := NewGenerator(nil).GeneratePIN(4, true); err != nil {
+		t.Errorf("GeneratePIN(4) without a floor error = %v", err)
+	}
+}
+
+func TestWithForceLowercase(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{Words: []string{"Alpha", "BRAVO", "charlie"}}, WithForceLowercase())
+	generators := map[string]func() (string, error){
+		"Generate": func() (string, error) { return g.Generate(20, 3, 3, true, true) },
+		"GenerateUppercaseRatio": func() (string, error) {
+			return g.GenerateWithConfig(GenerateConfig{Length: 20, AllowUppercase: true, AllowRepeat: true, UppercaseRatio: 0.5})
+		},
+		"GenerateWithMinimums": func() (string, error) { return g.GenerateWithMinimums(20, 2, 0, 2, 2, true) },
+		"GenerateFromPattern":  func() (string, error) { return g.GenerateFromPattern("UUUU-LLLL-DDDD") },
+		"GenerateCompliant": func() (string, error) {
+			pwd, _, err := g.GenerateCompliant("nist-800-63b", 20)
+			return pwd, err
+		},
+		"GenerateSplit": func() (string, error) {
+			_, pwd, err := g.GenerateSplit(20, 2)
+			return pwd, err
+		},
+		"GenerateMaskWithMinimums": func() (string, error) { return g.GenerateMaskWithMinimums("uuuuaaaa", nil) },
+		"GenerateHybrid":           func() (string, error) { return g.GenerateHybrid(4, 2, 1, true) },
+		"GeneratePassphrase":       func() (string, error) { return g.GeneratePassphrase(4, "-", true) },
+	}
+	for name, generate := range generators {
+		for range 50 {
+			pwd, err := generate()
+			if err != nil {
+				t.Fatalf("%s error = %v", name, err)
+			}
+			if strings.ToLower(pwd) != pwd {
+				t.Fatalf("%s = %q, want no uppercase", name, pwd)
+			}
+		}
+	}
+}
+
+func TestGenerateWithinAllowed(t *testing.T) {
+	g := NewGenerator(nil)
+	const allowed = "abcdefXYZ0123!#é"
+	for range 500 {
+		pwd, err := g.GenerateWithinAllowed(allowed, 10, 2, 1, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range pwd {
+			if !strings.ContainsRune(allowed, r) {
+				t.Fatalf("password %q contains %q, which is not allowed", pwd, r)
+			}
+		}
+		if digits, symbols := countClasses(g, pwd); utf8.RuneCountInString(pwd) != 10 || digits != 2 || symbols != 1 {
+			t.Fatalf("password %q is not made of 10 characters with 2 digits and 1 symbol", pwd)
+		}
+	}
+}
+
+func TestGenerateWithMinimums(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, allowRepeat := range []bool{true, false} {
+		for range 500 {
+			pwd, err := g.GenerateWithMinimums(16, 3, 2, 2, 1, allowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lower, upper, digits, symbols int
+			for _, r := range pwd {
+				switch {
+				case strings.ContainsRune(g.lowerLetters, r):
+					lower++
+				case strings.ContainsRune(g.upperLetters, r):
+					upper++
+				case strings.ContainsRune(g.digits, r):
+					digits++
+				case strings.ContainsRune(g.symbols, r):
+					symbols++
+				}
+			}
+			if utf8.RuneCountInString(pwd) != 16 || lower < 3 || upper < 2 || digits < 2 || symbols < 1 {
+				t.Fatalf("password %q has %d lowercase, %d uppercase, %d digits and %d symbols, want at least 3, 2, 2 and 1", pwd, lower, upper, digits, symbols)
+			}
+			if !allowRepeat && distinctCount(pwd) != 16 {
+				t.Fatalf("password %q repeats a character", pwd)
+			}
+		}
+	}
+	if _, err := g.GenerateWithMinimums(4, 2, 2, 1, 0, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateWithMinimums(minimums above the length) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestWithSymbolPlacementBias(t *testing.T) {
+	const runs = 20000
+	params := GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 1, AllowRepeat: true}
+
+	// Share of the symbols in the middle half of the passwords, by bias
+	middle := make([]float64, 0, 3)
+	for _, bias := range []float64{0, 1, 4} {
+		g := NewSeededGenerator(nil, 231, WithSymbolPlacementBias(bias))
+		digitsAt := make([]int, params.Length-params.NumSymbols)
+		inMiddle := 0
+		for range runs {
+			pwd, err := g.GenerateWithConfig(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			others := 0
+			for i, r := range []rune(pwd) {
+				if strings.ContainsRune(g.symbols, r) {
+					if i >= 3 && i < 9 {
+						inMiddle++
+					}
+					continue
+				}
+				if strings.ContainsRune(g.digits, r) {
+					digitsAt[others]++
+				}
+				others++
+			}
+		}
+		middle = append(middle, float64(inMiddle)/runs)
+
+		// The other characters stay uniformly placed between the symbols
+		// (chi-square critical value for 10 degrees of freedom and p = 0.001)
+		if stat := chiSquare(digitsAt, runs*2.0/11); stat > 29.59 {
+			t.Errorf("bias %v: digits not uniform over the positions: chi2 = %.1f, counts %v", bias, stat, digitsAt)
+		}
+	}
+	if middle[0] < 0.47 || middle[0] > 0.53 {
+		t.Errorf("bias 0: %.3f of the symbols in the middle half, want about 0.5", middle[0])
+	}
+	if !(middle[0]+0.1 < middle[1] && middle[1]+0.1 < middle[2]) {
+		t.Errorf("shares of the symbols in the middle half = %.3f, want them to grow with the bias", middle)
+	}
+}
+
+func TestWithRetryObserver(t *testing.T) {
+	var attempts []int
+	var reasons []string
+	observer := func(attempt int, reason string) {
+		attempts = append(attempts, attempt)
+		reasons = append(reasons, reason)
+	}
+
+	// Half of the candidates contain "aa"
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab"}, WithBlocklist([]string{"aa"}), WithRetryObserver(observer))
+	blocklist := g.checks[0]
+	rejected := 0
+	g.checks[0] = func(password string) string {
+		reason := blocklist(password)
+		if reason != "" {
+			rejected++
+		}
+		return reason
+	}
+	for range 200 {
+		attempts, reasons = attempts[:0], reasons[:0]
+		before := rejected
+		if _, err := g.Generate(4, 0, 0, false, true); err != nil {
+			t.Fatal(err)
+		}
+		if len(attempts) != rejected-before {
+			t.Fatalf("observer called %d times for %d rejected candidates", len(attempts), rejected-before)
+		}
+		for i, attempt := range attempts {
+			if attempt != i+1 || reasons[i] != `contains "aa"` {
+				t.Fatalf("observer called with (%d, %q), want (%d, %q)", attempt, reasons[i], i+1, `contains "aa"`)
+			}
+		}
+	}
+	if rejected == 0 {
+		t.Fatal("no candidate was rejected")
+	}
+
+	// Four candidates rejected by GenerateUntil
+	attempts, reasons = attempts[:0], reasons[:0]
+	calls := 0
+	_, err := g.GenerateUntil(func(string) bool { calls++; return calls > 4 }, 10, GenerateConfig{Length: 4, AllowRepeat: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for i, reason := range reasons {
+		if reason == "rejected by the accept function" {
+			if attempts[i] != n+1 {
+				t.Errorf("attempt %d reported as %d", n+1, attempts[i])
+			}
+			n++
+		}
+	}
+	if n != 4 {
+		t.Errorf("observer called %d times for the accept function, want 4", n)
+	}
+}
+
+// chanLimiter is a Limiter letting a caller through for each value sent on
+// its channel.
+type chanLimiter chan struct{}
+
+func (l chanLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-l:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestGenerateThrottled(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowRepeat: true}
+	limiter := make(chanLimiter)
+
+	// The generation waits until the limiter lets it through
+	done := make(chan error)
+	go func() {
+		_, err := g.GenerateThrottled(context.Background(), limiter, params)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("GenerateThrottled() returned %v before the limiter allowed it", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	limiter <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// A cancelled context stops the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := g.GenerateThrottled(ctx, limiter, params); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateThrottled(cancelled) error = %v, want %v", err, context.Canceled)
+	}
+
+	if _, err := g.GenerateThrottled(context.Background(), nil, params); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateThrottled(nil limiter) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGenerateFormat(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowRepeat: true}
+
+	// Insert a dash every 4 characters
+	grouped, err := g.GenerateFormat(params, func(pwd string) (string, error) {
+		chars := []rune(pwd)
+		groups := make([]string, 0, 3)
+		for i := 0; i < len(chars); i += 4 {
+			groups = append(groups, string(chars[i:i+4]))
+		}
+		return strings.Join(groups, "-"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups := strings.Split(grouped, "-"); len(groups) < 3 || utf8.RuneCountInString(grouped) != 14 {
+		t.Errorf("GenerateFormat(groups) = %q, want 3 groups of 4 characters", grouped)
+	}
+
+	upper, err := g.GenerateFormat(params, func(pwd string) (string, error) { return strings.ToUpper(pwd), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ToUpper(upper) != upper || utf8.RuneCountInString(upper) != 12 {
+		t.Errorf("GenerateFormat(upper) = %q, want 12 characters in uppercase", upper)
+	}
+
+	// The error of the format function is returned as is
+	errFormat := errors.New("format failed")
+	if pwd, err := g.GenerateFormat(params, func(string) (string, error) { return "", errFormat }); !errors.Is(err, errFormat) || pwd != "" {
+		t.Errorf("GenerateFormat(failing) = %q, %v, want %v", pwd, err, errFormat)
+	}
+}
+
+func TestWithStrictSymbolUniqueness(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab", Symbols: "!?#"}, WithStrictSymbolUniqueness())
+	letterRepeats := false
+	for range 200 {
+		pwd, err := g.Generate(10, 0, 3, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		symbols := filterPool(pwd, func(r rune) bool { return strings.ContainsRune(g.symbols, r) })
+		if utf8.RuneCountInString(symbols) != 3 || distinctCount(symbols) != 3 {
+			t.Fatalf("password %q does not have 3 distinct symbols", pwd)
+		}
+		letterRepeats = letterRepeats || distinctCount(pwd) < 10
+	}
+	if !letterRepeats {
+		t.Error("the letters never repeat although repeats are allowed")
+	}
+
+	_, err := g.Generate(10, 0, 4, false, true)
+	if !errors.Is(err, ErrSymbolsExceedsAvailable) {
+		t.Errorf("Generate(4 symbols out of 3) error = %v, want %v", err, ErrSymbolsExceedsAvailable)
+	}
+	if _, err := NewGenerator(&GeneratorInput{Symbols: "!?#"}).Generate(10, 0, 4, false, true); err != nil {
+		t.Errorf("Generate(4 symbols out of 3) without the option error = %v", err)
+	}
+}
+
+func TestWithMaxRunLength(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab", Digits: "0"}, WithMaxRunLength(2))
+	for range 500 {
+		pwd, err := g.Generate(12, 3, 0, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if longestRun(pwd) > 2 {
+			t.Fatalf("password %q has a run longer than 2", pwd)
+		}
+	}
+
+	// A limit not lower than the length changes nothing
+	params := GenerateConfig{Length: 8, NumDigits: 2, NumSymbols: 1, AllowRepeat: true}
+	for _, n := range []int{8, 9} {
+		with, without := NewSeededGenerator(nil, 215, WithMaxRunLength(n)), NewSeededGenerator(nil, 215)
+		for range 50 {
+			a, err := with.GenerateWithConfig(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b, err := without.GenerateWithConfig(params); err != nil || a != b {
+				t.Fatalf("WithMaxRunLength(%d) gives %q, want %q as without the option (%v)", n, a, b, err)
+			}
+		}
+	}
+	if pwd, err := NewGenerator(&GeneratorInput{LowerLetters: "a"}, WithMaxRunLength(4)).Generate(4, 0, 0, false, true); err != nil || pwd != "aaaa" {
+		t.Errorf("Generate(4) from a single character with a limit of 4 = %q, %v, want %q", pwd, err, "aaaa")
+	}
+
+	_, err := NewGenerator(&GeneratorInput{LowerLetters: "a"}, WithMaxRunLength(3)).Generate(4, 0, 0, false, true)
+	if !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("Generate(4) from a single character with a limit of 3 error = %v, want %v", err, ErrCannotSatisfy)
+	}
+}
+
+func TestURLSafe(t *testing.T) {
+	g := NewGenerator(nil, WithURLSafeSymbols())
+	// The non-ASCII letters are left out of GenerateURLSafe
+	accented := NewGenerator(&GeneratorInput{LowerLetters: "abcdéf"})
+	for range 200 {
+		pwd, err := g.Generate(20, 3, 4, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		safe, err := accented.GenerateURLSafe(32, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{pwd, safe} {
+			if url.QueryEscape(s) != s {
+				t.Fatalf("url.QueryEscape(%q) = %q, want it unchanged", s, url.QueryEscape(s))
+			}
+		}
+	}
+	if _, err := g.Generate(20, 3, 5, true, false); !errors.Is(err, ErrSymbolsExceedsAvailable) {
+		t.Errorf("Generate(5 symbols without repeats) error = %v, want %v", err, ErrSymbolsExceedsAvailable)
+	}
+}
+
+func TestGenerateToJSONFile(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true}
+	path := filepath.Join(t.TempDir(), "passwords.json")
+	type entry struct {
+		ID       int    `json:"id"`
+		Password string `json:"password"`
+	}
+	readEntries := func() []entry {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entries []entry
+		if err := json.Unmarshal(content, &entries); err != nil {
+			t.Fatalf("file content %s is not a JSON array: %v", content, err)
+		}
+		return entries
+	}
+
+	if err := g.GenerateToJSONFile(path, 25, params, false); err != nil {
+		t.Fatal(err)
+	}
+	entries := readEntries()
+	if len(entries) != 25 {
+		t.Fatalf("got %d entries, want 25", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.ID != i+1 || utf8.RuneCountInString(entry.Password) != 12 {
+			t.Errorf("entry %d = %+v, want id %d and a password of 12 characters", i, entry, i+1)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file permissions = %v, want 0600", perm)
+	}
+
+	// The file is only replaced when forced
+	if err := g.GenerateToJSONFile(path, 3, params, false); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("GenerateToJSONFile(existing file) error = %v, want %v", err, fs.ErrExist)
+	}
+	if len(readEntries()) != 25 {
+		t.Error("the existing file was modified without force")
+	}
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateToJSONFile(path, 3, params, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(readEntries()) != 3 {
+		t.Error("the existing file was not replaced with force")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("permissions of the replaced file = %v (%v), want 0600", info.Mode().Perm(), err)
+	}
+
+	if err := g.GenerateToJSONFile(path, 0, params, true); err != nil || len(readEntries()) != 0 {
+		t.Errorf("GenerateToJSONFile(0) error = %v, want an empty array", err)
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	content := "# company alphabet\n\nlower = abcdef\n  upper=XYZ  \nsymbols=!#\n"
+	input, err := parseCharset(strings.NewReader(content), "charset.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GeneratorInput{LowerLetters: "abcdef", UpperLetters: "XYZ", Symbols: "!#"}
+	if input.LowerLetters != want.LowerLetters || input.UpperLetters != want.UpperLetters || input.Digits != "" || input.Symbols != want.Symbols {
+		t.Errorf("parseCharset() = %+v, want %+v", *input, want)
+	}
+
+	for _, tt := range []struct {
+		content, line string
+	}{
+		{"lower=abc\nupper ABC\n", "charset.txt:2:"},
+		{"# comment\n\nvowels=aeiou\n", "charset.txt:3:"},
+	} {
+		_, err := parseCharset(strings.NewReader(tt.content), "charset.txt")
+		if !errors.Is(err, ErrInvalidCharsetFile) || !strings.Contains(err.Error(), tt.line) {
+			t.Errorf("parseCharset(%q) error = %v, want %v at %s", tt.content, err, ErrInvalidCharsetFile, tt.line)
+		}
+	}
+}
+
+func TestNewGeneratorFromCharsetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "charset.txt")
+	if err := os.WriteFile(path, []byte("lower=abc\ndigits=789\nsymbols=@\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGeneratorFromCharsetFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 100 {
+		pwd, err := g.Generate(10, 3, 1, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Trim(pwd, "abc"+UpperLetters+"789@") != "" {
+			t.Fatalf("password %q uses characters outside the charset file and the default uppercase letters", pwd)
+		}
+	}
+
+	if _, err := NewGeneratorFromCharsetFile(filepath.Join(t.TempDir(), "missing.txt")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("NewGeneratorFromCharsetFile(missing) error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestGenerateCompliant(t *testing.T) {
+	g := NewGenerator(nil)
+	for profile, p := range complianceProfiles {
+		for _, length := range []int{p.minLength, p.minLength + 8} {
+			for range 100 {
+				pwd, note, err := g.GenerateCompliant(profile, length)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if utf8.RuneCountInString(pwd) != length || note != p.note {
+					t.Fatalf("GenerateCompliant(%s, %d) = %q, %q, want %d characters and the note of the profile", profile, length, pwd, note, length)
+				}
+				// PCI DSS requires both alphabetic and numeric characters
+				digits, symbols := countClasses(g, pwd)
+				if profile == "pci-dss-4.0" && (digits != 2 || symbols != 1) {
+					t.Fatalf("GenerateCompliant(%s) = %q, want 2 digits, 1 symbol and letters", profile, pwd)
+				}
+			}
+		}
+		if _, _, err := g.GenerateCompliant(profile, p.minLength-1); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateCompliant(%s, %d) error = %v, want %v", profile, p.minLength-1, err, ErrInvalidArgument)
+		}
+	}
+	if _, _, err := g.GenerateCompliant("fips-140", 16); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("GenerateCompliant(unknown) error = %v, want %v", err, ErrUnknownProfile)
+	}
+}
+
+// shortReader returns at most one byte per read.
+type shortReader struct{ r io.Reader }
+
+func (s *shortReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return s.r.Read(p)
+}
+
+func TestGenerateWithSourceInfo(t *testing.T) {
+	params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 2, AllowUppercase: true}
+	pwd, source, err := NewGenerator(nil).GenerateWithSourceInfo(params)
+	if err != nil || source != "crypto/rand" || utf8.RuneCountInString(pwd) != 16 {
+		t.Errorf("GenerateWithSourceInfo() = %q, %q, %v, want 16 characters from crypto/rand", pwd, source, err)
+	}
+
+	g := NewGenerator(nil, WithReader(&shortReader{mathrand.NewChaCha8([32]byte{254})}))
+	for range 100 {
+		pwd, source, err := g.GenerateWithSourceInfo(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digits, symbols := countClasses(g, pwd)
+		if source != "*passwordgenerator.shortReader" || utf8.RuneCountInString(pwd) != 16 || digits != 3 || symbols != 2 {
+			t.Fatalf("GenerateWithSourceInfo(short reads) = %q, %q, want a full password from *passwordgenerator.shortReader", pwd, source)
+		}
+	}
+}
+
+func TestGenerateAllDigitsWithoutRepeat(t *testing.T) {
+	g := NewGenerator(nil)
+	start := time.Now()
+	for range 1000 {
+		pwd, err := g.Generate(10, 10, 0, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if utf8.RuneCountInString(pwd) != 10 || distinctCount(pwd) != 10 || strings.Trim(pwd, Digits) != "" {
+			t.Fatalf("Generate(10 digits without repeat) = %q, want the 10 digits", pwd)
+		}
+	}
+	// A few milliseconds are expected, the bound leaves room for slow machines
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("1000 passwords of the 10 digits took %v", elapsed)
+	}
+}
+
+func TestEntropyKnownValues(t *testing.T) {
+	small := NewGenerator(&GeneratorInput{LowerLetters: "ab", UpperLetters: "AB", Digits: "01", Symbols: "!"})
+	for _, tt := range []struct {
+		g      *Generator
+		params GenerateConfig
+		want   float64
+	}{
+		// 26^8 lowercase passwords
+		{NewGenerator(nil), GenerateConfig{Length: 8, AllowRepeat: true}, 37.603517745128734},
+		// 52^12 mixed-case passwords
+		{NewGenerator(nil), GenerateConfig{Length: 12, AllowUppercase: true, AllowRepeat: true}, 68.4052766176931},
+		// 10*9*8*7 PINs without repeat
+		{NewGenerator(nil), GenerateConfig{Length: 4, NumDigits: 4}, 12.29920801838728},
+		// 2^2 letters, 2 digits, 1 symbol and 4!/(2!1!1!) arrangements
+		{small, GenerateConfig{Length: 4, NumDigits: 1, NumSymbols: 1, AllowRepeat: true}, 6.584962500721156},
+		// 3! orders of the letters
+		{NewGenerator(&GeneratorInput{LowerLetters: "abc"}), GenerateConfig{Length: 3}, 2.584962500721156},
+		// More symbols than available without repeat
+		{small, GenerateConfig{Length: 4, NumSymbols: 2}, 0},
+	} {
+		if got := tt.g.Entropy(tt.params); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Entropy(%+v) = %v, want %v", tt.params, got, tt.want)
+		}
+		p := tt.params
+		if got := tt.g.EntropyBits(p.Length, p.NumDigits, p.NumSymbols, p.AllowUppercase, p.AllowRepeat); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EntropyBits(%+v) = %v, want %v", p, got, tt.want)
+		}
+	}
+}
+
+func TestExcludeAmbiguous(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{ExcludeAmbiguous: true})
+	for range 500 {
+		pwd, err := g.Generate(24, 6, 4, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsAny(pwd, AmbiguousChars) {
+			t.Fatalf("password %q contains an ambiguous character of %q", pwd, AmbiguousChars)
+		}
+	}
+
+	// The availability checks count the remaining digits only
+	remaining := distinctCount(filterPool(Digits, func(r rune) bool { return !strings.ContainsRune(AmbiguousChars, r) }))
+	if _, err := g.Generate(remaining, remaining, 0, false, false); err != nil {
+		t.Errorf("Generate(%d digits) error = %v", remaining, err)
+	}
+	if _, err := g.Generate(remaining+1, remaining+1, 0, false, false); !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("Generate(%d digits) error = %v, want %v", remaining+1, err, ErrDigitsExceedsAvailable)
+	}
+}
+
+// zeroReader is a fixed source of random bytes, all zero.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestReaderReproducible(t *testing.T) {
+	// Every draw of a zero source picks the first candidate
+	pwd, err := NewGenerator(&GeneratorInput{Reader: zeroReader{}}).Generate(6, 2, 1, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "aa00~a"; pwd != want {
+		t.Errorf("Generate() from zero bytes = %q, want %q", pwd, want)
+	}
+
+	params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 3, AllowUppercase: true}
+	seeded := NewGenerator(&GeneratorInput{Reader: mathrand.NewChaCha8([32]byte{9})})
+	reset := NewGenerator(nil)
+	reset.SetReader(mathrand.NewChaCha8([32]byte{9}))
+	for range 20 {
+		a, err := seeded.GenerateWithConfig(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, err := reset.GenerateWithConfig(params); err != nil || a != b {
+			t.Fatalf("two generators reading the same stream give %q and %q (%v)", a, b, err)
+		}
+	}
+}
+
+// classPattern replaces each character of a password by its class: L
+// (letter), D (digit) or S (symbol).
+func classPattern(g *Generator, password string) string {
+	pattern := []rune(password)
+	for i, r := range pattern {
+		switch {
+		case strings.ContainsRune(g.digits, r):
+			pattern[i] = 'D'
+		case strings.ContainsRune(g.symbols, r):
+			pattern[i] = 'S'
+		default:
+			pattern[i] = 'L'
+		}
+	}
+	return string(pattern)
+}
+
+func TestWithPlacementSeed(t *testing.T) {
+	params := GenerateConfig{Length: 16, NumDigits: 4, NumSymbols: 3, AllowUppercase: true, AllowRepeat: true}
+	a, b := NewGenerator(nil, WithPlacementSeed(259)), NewGenerator(nil, WithPlacementSeed(259))
+	patterns := make(map[string]bool)
+	for range 50 {
+		pa, err := a.GenerateWithConfig(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pb, err := b.GenerateWithConfig(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if classPattern(a, pa) != classPattern(b, pb) {
+			t.Fatalf("passwords %q and %q do not have the same placement", pa, pb)
+		}
+		if pa == pb {
+			t.Fatalf("both generators drew %q, the characters should still be random", pa)
+		}
+		patterns[classPattern(a, pa)] = true
+	}
+	// The placements still change from one password to the next
+	if len(patterns) < 40 {
+		t.Errorf("got %d placements for 50 passwords", len(patterns))
+	}
+}
+
+func TestGenerateN(t *testing.T) {
+	g := NewGenerator(nil)
+	passwords, err := g.GenerateN(1000, 8, 2, 1, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passwords) != 1000 {
+		t.Fatalf("got %d passwords, want 1000", len(passwords))
+	}
+	seen := make(map[string]bool, len(passwords))
+	for _, pwd := range passwords {
+		if seen[pwd] {
+			t.Fatalf("password %q appears twice", pwd)
+		}
+		seen[pwd] = true
+	}
+
+	// Only 4 passwords of 2 letters out of "ab" exist
+	small := NewGenerator(&GeneratorInput{LowerLetters: "ab"})
+	if passwords, err := small.GenerateN(4, 2, 0, 0, false, true); err != nil || len(passwords) != 4 {
+		t.Errorf("GenerateN(4 of 4) = %q, %v, want the 4 passwords", passwords, err)
+	}
+	_, err = small.GenerateN(5, 2, 0, 0, false, true)
+	if !errors.Is(err, ErrTooFewDistinct) || !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateN(5 of 4) error = %v, want %v", err, ErrTooFewDistinct)
+	}
+	if _, err := g.GenerateN(-1, 8, 2, 1, true, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateN(-1) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestWithNoKeyboardWalk(t *testing.T) {
+	walks := avoidKeyboardWalks(2)
+	for _, password := range []string{"qaz", "zaq", "wsx", "QAZ", "x7qaz1", "qwe", "edc", "4rf"} {
+		if walks(password) == "" {
+			t.Errorf("%q is accepted, want it rejected as a keyboard walk", password)
+		}
+	}
+	for _, password := range []string{"qpz", "qa1m", "aab", "zqx", "q!a", "p0m"} {
+		if reason := walks(password); reason != "" {
+			t.Errorf("%q is rejected (%s), want it accepted", password, reason)
+		}
+	}
+
+	g := NewGenerator(nil, WithNoKeyboardWalk(2))
+	for range 200 {
+		pwd, err := g.Generate(16, 3, 2, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reason := walks(pwd); reason != "" {
+			t.Fatalf("password %q is generated despite the %s", pwd, reason)
+		}
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	words := []string{"correct", "horse", "battery", "staple"}
+	g := NewGenerator(&GeneratorInput{Words: words})
+	for range 100 {
+		passphrase, err := g.GeneratePassphrase(5, "-", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts := strings.Split(passphrase, "-")
+		if len(parts) != 5 {
+			t.Fatalf("GeneratePassphrase(5) = %q, want 5 words", passphrase)
+		}
+		for _, part := range parts {
+			if !slices.Contains(words, strings.ToLower(part[:1])+part[1:]) || strings.ToUpper(part[:1]) != part[:1] {
+				t.Fatalf("word %q of %q is not a capitalized word of the list", part, passphrase)
+			}
+		}
+	}
+
+	digit := NewGenerator(&GeneratorInput{Words: words}, WithPassphraseDigit())
+	passphrase, err := digit.GeneratePassphrase(3, " ", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parts := strings.Fields(passphrase); len(parts) != 3 || !strings.ContainsAny(passphrase[len(passphrase)-1:], Digits) {
+		t.Errorf("GeneratePassphrase(3) with a digit = %q, want 3 words ending with a digit", passphrase)
+	}
+
+	// The words are drawn from the injected source
+	seeded := func() *Generator { return NewGenerator(&GeneratorInput{Reader: mathrand.NewChaCha8([32]byte{26, 1})}) }
+	a, b := seeded(), seeded()
+	for range 20 {
+		pa, err := a.GeneratePassphrase(6, " ", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pb, err := b.GeneratePassphrase(6, " ", false); err != nil || pa != pb {
+			t.Fatalf("two generators reading the same stream give %q and %q (%v)", pa, pb, err)
+		}
+	}
+	if _, err := g.GeneratePassphrase(0, "-", false); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GeneratePassphrase(0) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGenerateNotInFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.txt")
+	params := GenerateConfig{Length: 2, AllowRepeat: true}
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab"})
+
+	// A missing history is created
+	first, err := g.GenerateNotInFile(path, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != first+"\n" {
+		t.Fatalf("history = %q, %v, want %q", content, err, first+"\n")
+	}
+
+	// Only "bb" is not in the history (written without a final newline)
+	if err := os.WriteFile(path, []byte("aa\nab\r\nba"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pwd, err := g.GenerateNotInFile(path, params)
+	if err != nil || pwd != "bb" {
+		t.Fatalf("GenerateNotInFile() = %q, %v, want %q", pwd, err, "bb")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "aa\nab\r\nba\nbb\n" {
+		t.Fatalf("history = %q, %v, want the new password appended", content, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("permissions of the history = %v (%v), want 0600", info.Mode().Perm(), err)
+	}
+
+	// Every password is in the history, which is left unchanged
+	if _, err := g.GenerateNotInFile(path, params); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateNotInFile(full history) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+	if again, err := os.ReadFile(path); err != nil || string(again) != string(content) {
+		t.Errorf("history = %q, %v, want it unchanged", again, err)
+	}
+
+	// The temporary files are not left behind
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("directory has %d entries (%v), want only the history", len(entries), err)
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	input := &GeneratorInput{
+		LowerLetters:    "acmepsword",
+		Digits:          "1234",
+		Blocklist:       []string{"ACME", "pass", "12"},
+		RejectSequences: true,
+	}
+	g := NewGenerator(input)
+	sequences := avoidSequences(sequenceLength)
+	for range 300 {
+		pwd, err := g.Generate(10, 3, 0, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lower := strings.ToLower(pwd)
+		for _, banned := range []string{"acme", "pass", "12"} {
+			if strings.Contains(lower, banned) {
+				t.Fatalf("password %q contains the banned %q", pwd, banned)
+			}
+		}
+		if reason := sequences(pwd); reason != "" {
+			t.Fatalf("password %q is generated despite the %s", pwd, reason)
+		}
+	}
+
+	// Every character is banned, even the password built directly fails
+	_, err := NewGenerator(&GeneratorInput{LowerLetters: "ab", Blocklist: []string{"a", "B"}}).Generate(6, 0, 0, false, true)
+	if !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("Generate(unsatisfiable blocklist) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+}
+
+func TestGenerateContextCancelled(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowRepeat: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.GenerateContext(ctx, params); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateContext(cancelled) error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := g.GenerateNContext(ctx, 1000, 12, 2, 2, false, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateNContext(cancelled) error = %v, want %v", err, context.Canceled)
+	}
+
+	// A cancellation during a batch stops it
+	ctx, cancel = context.WithCancel(context.Background())
+	rejections := 0
+	slow := NewGenerator(&GeneratorInput{LowerLetters: "ab"}, WithBlocklist([]string{"aa"}), WithRetryObserver(func(int, string) {
+		if rejections++; rejections == 10 {
+			cancel()
+		}
+	}))
+	if _, err := slow.GenerateNContext(ctx, 100000, 20, 0, 0, false, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateNContext(cancelled during the batch) error = %v, want %v", err, context.Canceled)
+	}
+
+	if pwd, err := g.GenerateContext(context.Background(), params); err != nil || utf8.RuneCountInString(pwd) != 12 {
+		t.Errorf("GenerateContext() = %q, %v, want a password of 12 characters", pwd, err)
+	}
+}
+
+func TestMustGenerate(t *testing.T) {
+	g := NewGenerator(nil)
+	if pwd := g.MustGenerate(12, 2, 2, true, true); utf8.RuneCountInString(pwd) != 12 {
+		t.Errorf("MustGenerate(12) = %q, want 12 characters", pwd)
+	}
+	for _, args := range [][3]int{{-1, 0, 0}, {4, 3, 3}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MustGenerate(%v) did not panic", args)
+				}
+			}()
+			g.MustGenerate(args[0], args[1], args[2], true, true)
+		}()
+	}
+}
+
+func TestMultibyteSymbols(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{Symbols: "€£¥😀"})
+	for range 300 {
+		pwd, err := g.Generate(10, 2, 4, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !utf8.ValidString(pwd) || utf8.RuneCountInString(pwd) != 10 || distinctCount(pwd) != 10 {
+			t.Fatalf("password %q is not made of 10 distinct whole characters", pwd)
+		}
+		if digits, symbols := countClasses(g, pwd); digits != 2 || symbols != 4 {
+			t.Fatalf("password %q has %d digits and %d symbols, want 2 and 4", pwd, digits, symbols)
+		}
+	}
+	if n := g.MaxUniqueSymbols(); n != 4 {
+		t.Errorf("MaxUniqueSymbols() = %d, want 4", n)
+	}
+	if _, err := g.Generate(10, 2, 5, true, false); !errors.Is(err, ErrSymbolsExceedsAvailable) {
+		t.Errorf("Generate(5 of the 4 symbols) error = %v, want %v", err, ErrSymbolsExceedsAvailable)
+	}
+}
+
+func TestWithRequireEachEnabledClass(t *testing.T) {
+	g := NewGenerator(nil, WithRequireEachEnabledClass())
+	for _, tt := range []struct {
+		length, numDigits, numSymbols int
+		allowUpper                    bool
+	}{
+		{2, 0, 0, true},
+		{4, 1, 1, true},
+		{3, 1, 1, false},
+		{8, 0, 0, true},
+	} {
+		for range 500 {
+			pwd, err := g.Generate(tt.length, tt.numDigits, tt.numSymbols, tt.allowUpper, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.ContainsAny(pwd, g.lowerLetters) {
+				t.Fatalf("password %q has no lowercase letter", pwd)
+			}
+			if tt.allowUpper != strings.ContainsAny(pwd, g.upperLetters) {
+				t.Fatalf("password %q: uppercase letter present = %v, want %v", pwd, !tt.allowUpper, tt.allowUpper)
+			}
+			if digits, symbols := countClasses(g, pwd); digits != tt.numDigits || symbols != tt.numSymbols {
+				t.Fatalf("password %q has %d digits and %d symbols, want %d and %d", pwd, digits, symbols, tt.numDigits, tt.numSymbols)
+			}
+		}
+	}
+	// No room for both letter cases
+	if _, err := g.Generate(3, 1, 1, true, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Generate(1 letter, 2 cases) error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if _, err := g.Generate(1, 0, 0, true, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Generate(1 letter, 2 cases) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestNewGeneratorDeduplicates(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{Digits: "0011223344", Symbols: "!!@"})
+	if g.digits != "01234" || g.symbols != "!@" {
+		t.Errorf("digits, symbols = %q, %q, want %q, %q", g.digits, g.symbols, "01234", "!@")
+	}
+	// All the digits are used once
+	for range 100 {
+		pwd, err := g.Generate(5, 5, 0, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if distinctCount(pwd) != 5 {
+			t.Fatalf("password %q repeats a digit", pwd)
+		}
+	}
+	if _, err := g.Generate(6, 6, 0, false, false); !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("Generate(6 of the 5 digits) error = %v, want %v", err, ErrDigitsExceedsAvailable)
+	}
+}
+
+func TestNewGeneratorValidated(t *testing.T) {
+	if _, err := NewGeneratorValidated(nil); err != nil {
+		t.Errorf("NewGeneratorValidated(nil) error = %v", err)
+	}
+	if _, err := NewGeneratorValidated(&GeneratorInput{Digits: "0123", Symbols: "!@"}); err != nil {
+		t.Errorf("NewGeneratorValidated(distinct sets) error = %v", err)
+	}
+	for _, i := range []*GeneratorInput{
+		{Digits: "0011223344"},
+		{LowerLetters: "abca"},
+		{Symbols: "   "},
+	} {
+		if _, err := NewGeneratorValidated(i); !errors.Is(err, ErrInvalidCharset) {
+			t.Errorf("NewGeneratorValidated(%+v) error = %v, want %v", *i, err, ErrInvalidCharset)
+		}
+	}
+}
+
+func TestUppercaseRatio(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, ratio := range []float64{0.2, 0.5, 0.9} {
+		upper, letters := 0, 0
+		for range 500 {
+			pwd, err := g.GenerateWithConfig(GenerateConfig{Length: 20, NumDigits: 2, AllowUppercase: true, AllowRepeat: true, UppercaseRatio: ratio})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range pwd {
+				if strings.ContainsRune(g.upperLetters, r) {
+					upper++
+				}
+			}
+			letters += 18
+		}
+		if got := float64(upper) / float64(letters); math.Abs(got-ratio) > 0.02 {
+			t.Errorf("UppercaseRatio %v: observed ratio %v", ratio, got)
+		}
+	}
+	for _, cfg := range []GenerateConfig{
+		{Length: 10, AllowUppercase: true, UppercaseRatio: 1.5},
+		{Length: 10, AllowUppercase: true, UppercaseRatio: -0.1},
+		{Length: 10, UppercaseRatio: 0.5},
+	} {
+		if _, err := g.GenerateWithConfig(cfg); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateWithConfig(%+v) error = %v, want %v", cfg, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestNoLeadingTrailingSymbol(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, tt := range []struct{ leading, trailing bool }{{true, false}, {false, true}, {true, true}} {
+		for range 500 {
+			pwd, err := g.GenerateWithConfig(GenerateConfig{Length: 6, NumDigits: 2, NumSymbols: 2, AllowUppercase: true, AllowRepeat: true, NoLeadingSymbol: tt.leading, NoTrailingSymbol: tt.trailing})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pattern := classPattern(g, pwd)
+			if tt.leading && pattern[0] != 'L' || tt.trailing && pattern[len(pattern)-1] != 'L' {
+				t.Fatalf("password %q (%+v) does not start or end with a letter", pwd, tt)
+			}
+		}
+	}
+	// Only one letter for both edges
+	if _, err := g.GenerateWithConfig(GenerateConfig{Length: 5, NumDigits: 2, NumSymbols: 2, NoLeadingSymbol: true, NoTrailingSymbol: true}); !errors.Is(err, ErrEdgeClassNotAllowed) {
+		t.Errorf("GenerateWithConfig(1 letter, 2 edges) error = %v, want %v", err, ErrEdgeClassNotAllowed)
+	}
+}
+
+func TestNewSeededGenerator(t *testing.T) {
+	params := GenerateConfig{Length: 16, NumDigits: 3, NumSymbols: 3, AllowUppercase: true}
+	generate := func(seed int64) []string {
+		passwords, err := NewSeededGenerator(nil, seed).GenerateMany(5, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return passwords
+	}
+	a, b, c := generate(280), generate(280), generate(281)
+	if !slices.Equal(a, b) {
+		t.Errorf("same seed: %q != %q", a, b)
+	}
+	if slices.Equal(a, c) {
+		t.Errorf("different seeds: both %q", a)
+	}
+	// The reader of the configuration is replaced
+	if d, _ := NewSeededGenerator(&GeneratorInput{Reader: zeroReader{}}, 280).GenerateMany(5, params); !slices.Equal(a, d) {
+		t.Errorf("seed with a reader: %q != %q", d, a)
+	}
+}
+
+type errReader struct{ err error }
+
+func (e errReader) Read(p []byte) (int, error) {
+	return 0, e.err
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := NewGenerator(nil).SelfTest(); err != nil {
+		t.Errorf("SelfTest(crypto/rand) error = %v", err)
+	}
+	broken := errors.New("broken device")
+	if err := NewGenerator(&GeneratorInput{Reader: errReader{broken}}).SelfTest(); !errors.Is(err, broken) {
+		t.Errorf("SelfTest(erroring reader) error = %v, want %v", err, broken)
+	}
+	if err := NewGenerator(&GeneratorInput{Reader: zeroReader{}}).SelfTest(); !errors.Is(err, ErrBrokenRandomSource) {
+		t.Errorf("SelfTest(constant reader) error = %v, want %v", err, ErrBrokenRandomSource)
+	}
+}
+
+func TestGenerateWeighted(t *testing.T) {
+	g := NewGenerator(nil)
+	var counts [3]int
+	for range 500 {
+		pwd, err := g.GenerateWeighted(20, 60, 25, 15, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digits, symbols := countClasses(g, pwd)
+		counts[0] += 20 - digits - symbols
+		counts[1] += digits
+		counts[2] += symbols
+	}
+	for i, want := range []float64{0.60, 0.25, 0.15} {
+		if got := float64(counts[i]) / 10000; math.Abs(got-want) > 0.02 {
+			t.Errorf("class %d: observed proportion %v, want %v", i, got, want)
+		}
+	}
+	// A class with a zero weight is never drawn
+	for range 100 {
+		pwd, err := g.GenerateWeighted(12, 1, 0, 1, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if digits, _ := countClasses(g, pwd); digits != 0 {
+			t.Fatalf("password %q has %d digits, want 0", pwd, digits)
+		}
+	}
+	for _, w := range [][3]float64{{0, 0, 0}, {-1, 1, 1}, {math.NaN(), 1, 1}, {math.Inf(1), 1, 1}} {
+		if _, err := g.GenerateWeighted(12, w[0], w[1], w[2], true, true); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateWeighted(%v) error = %v, want %v", w, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	g := NewGenerator(nil)
+	_, err := g.Generate(30, 30, 0, true, false)
+	if !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Fatalf("Generate(30 unique digits) error = %v, want %v", err, ErrDigitsExceedsAvailable)
+	}
+	var gerr *GenerateError
+	if !errors.As(err, &gerr) {
+		t.Fatalf("Generate(30 unique digits) error %T is not a *GenerateError", err)
+	}
+	want := GenerateError{Reason: "requested 30 unique digits but only 10 available", Length: 30, NumDigits: 30, PoolSize: 10, Err: ErrDigitsExceedsAvailable}
+	if *gerr != want {
+		t.Errorf("GenerateError = %+v, want %+v", *gerr, want)
+	}
+	if msg := err.Error(); msg != ErrDigitsExceedsAvailable.Error()+": "+want.Reason {
+		t.Errorf("Error() = %q", msg)
+	}
+
+	_, err = g.Generate(4, 3, 3, true, true)
+	if !errors.As(err, &gerr) || !errors.Is(err, ErrExceedsTotalLength) {
+		t.Fatalf("Generate(6 of 4 characters) error = %v, want a *GenerateError wrapping %v", err, ErrExceedsTotalLength)
+	}
+	if gerr.Length != 4 || gerr.NumDigits != 3 || gerr.NumSymbols != 3 || gerr.PoolSize != 0 {
+		t.Errorf("GenerateError = %+v", *gerr)
+	}
+	if errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("error %v wraps %v", err, ErrDigitsExceedsAvailable)
+	}
+}
+
+func TestGenerateMatching(t *testing.T) {
+	g := NewGenerator(nil)
+	re := regexp.MustCompile(`[0-9].*[A-Z]|[A-Z].*[0-9]`)
+	for range 200 {
+		pwd, err := g.GenerateMatching(re, 8, 1, 0, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !re.MatchString(pwd) {
+			t.Fatalf("password %q does not match %v", pwd, re)
+		}
+	}
+
+	// Never matched: the cap stops the attempts
+	attempts := 0
+	g = NewGenerator(nil, WithMaxAttempts(7), WithRetryObserver(func(attempt int, reason string) { attempts = attempt }))
+	if _, err := g.GenerateMatching(regexp.MustCompile(`^[0-9]+$`), 8, 1, 0, true, true); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateMatching(never matched) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+	if attempts != 7 {
+		t.Errorf("GenerateMatching(never matched) made %d attempts, want 7", attempts)
+	}
+	if _, err := g.GenerateMatching(nil, 8, 1, 0, true, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateMatching(nil) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestMask(t *testing.T) {
+	for _, tt := range []struct {
+		password       string
+		prefix, suffix int
+		want           string
+	}{
+		{"Ab3defghf7", 3, 2, "Ab3●●●●●f7"},
+		{"Ab3defghf7", 0, 0, "●●●●●●●●●●"},
+		{"Ab3defghf7", -1, 2, "●●●●●●●●f7"},
+		// Short passwords are fully masked
+		{"abcde", 3, 2, "●●●●●"},
+		{"abc", 3, 2, "●●●"},
+		{"a", 1, 0, "●"},
+		{"", 3, 2, ""},
+		// Multibyte characters count once
+		{"éà€😀ü漢字", 2, 1, "éà●●●●字"},
+		{"€😀", 1, 1, "●●"},
+	} {
+		if got := Mask(tt.password, tt.prefix, tt.suffix, '●'); got != tt.want {
+			t.Errorf("Mask(%q, %d, %d) = %q, want %q", tt.password, tt.prefix, tt.suffix, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateShardedBatch(t *testing.T) {
+	g := NewGenerator(nil)
+	prefixes := []string{"eu-", "us-", "ap-"}
+	batch, err := g.GenerateShardedBatch(prefixes, 50, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != len(prefixes) {
+		t.Fatalf("GenerateShardedBatch returned %d shards, want %d", len(batch), len(prefixes))
+	}
+	for _, prefix := range prefixes {
+		ids := batch[prefix]
+		if len(ids) != 50 {
+			t.Errorf("shard %q has %d identifiers, want 50", prefix, len(ids))
+		}
+		seen := make(map[string]bool)
+		for _, id := range ids {
+			body, ok := strings.CutPrefix(id, prefix)
+			if !ok || len(body) != 6 || strings.Trim(body, g.lowerLetters+g.digits) != "" {
+				t.Errorf("identifier %q is not %q followed by 6 lowercase letters and digits", id, prefix)
+			}
+			if seen[body] {
+				t.Errorf("shard %q repeats the body %q", prefix, body)
+			}
+			seen[body] = true
+		}
+	}
+
+	// The whole space of 3 characters on 2 positions
+	g = NewGenerator(&GeneratorInput{LowerLetters: "ab", Digits: "0"})
+	batch, err = g.GenerateShardedBatch([]string{"x"}, 9, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := slices.Sorted(slices.Values(batch["x"])); !slices.Equal(ids, []string{"x00", "x0a", "x0b", "xa0", "xaa", "xab", "xb0", "xba", "xbb"}) {
+		t.Errorf("GenerateShardedBatch(whole space) = %q", ids)
+	}
+	if _, err := g.GenerateShardedBatch([]string{"x"}, 10, 2); !errors.Is(err, ErrCodeSpaceTooSmall) {
+		t.Errorf("GenerateShardedBatch(10 of 9 bodies) error = %v, want %v", err, ErrCodeSpaceTooSmall)
+	}
+	for _, args := range []struct {
+		prefixes          []string
+		perPrefix, length int
+	}{{nil, 1, 2}, {[]string{"x"}, 0, 2}, {[]string{"x"}, 1, 0}, {[]string{"x", "x"}, 1, 2}} {
+		if _, err := g.GenerateShardedBatch(args.prefixes, args.perPrefix, args.length); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateShardedBatch(%+v) error = %v, want %v", args, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateUsername(t *testing.T) {
+	g := NewGenerator(nil)
+	lengths := make(map[int]bool)
+	for range 1000 {
+		username, err := g.GenerateUsername(3, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths[len(username)] = true
+		if len(username) < 3 || len(username) > 8 {
+			t.Fatalf("username %q has %d characters, want between 3 and 8", username, len(username))
+		}
+		if strings.Trim(username, g.lowerLetters+g.digits) != "" || !strings.ContainsRune(g.lowerLetters, rune(username[0])) {
+			t.Fatalf("username %q is not lowercase alphanumeric starting with a letter", username)
+		}
+		if strings.Contains(classPattern(g, username), "DDD") {
+			t.Fatalf("username %q has more than 2 consecutive digits", username)
+		}
+	}
+	if len(lengths) != 6 {
+		t.Errorf("usernames have %d different lengths, want 6", len(lengths))
+	}
+	if username, err := g.GenerateUsername(1, 1); err != nil || len(username) != 1 {
+		t.Errorf("GenerateUsername(1, 1) = %q, %v", username, err)
+	}
+	for _, bounds := range [][2]int{{0, 5}, {6, 5}} {
+		if _, err := g.GenerateUsername(bounds[0], bounds[1]); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateUsername(%d, %d) error = %v, want %v", bounds[0], bounds[1], err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestDigitsToWords(t *testing.T) {
+	for s, want := range map[string]string{
+		"0123456789": "zero one two three four five six seven eight nine",
+		"42":         "four two",
+		"4-2 a":      "four two",
+		"":           "",
+	} {
+		if got := DigitsToWords(s); got != want {
+			t.Errorf("DigitsToWords(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestGenerateSpokenPIN(t *testing.T) {
+	g := NewGenerator(nil)
+	words := strings.Fields(DigitsToWords(Digits))
+	for range 100 {
+		pin, spoken, err := g.GenerateSpokenPIN(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spokenWords := strings.Fields(spoken)
+		if len(pin) != 6 || len(spokenWords) != 6 {
+			t.Fatalf("GenerateSpokenPIN(6) = %q, %q, want 6 digits and 6 words", pin, spoken)
+		}
+		for i, r := range pin {
+			if r < '0' || r > '9' || spokenWords[i] != words[r-'0'] {
+				t.Fatalf("spoken form %q does not match the PIN %q", spoken, pin)
+			}
+		}
+	}
+	if _, _, err := g.GenerateSpokenPIN(0); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateSpokenPIN(0) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGenerateSalt(t *testing.T) {
+	salts := make(map[string]bool)
+	for range 100 {
+		salt, err := GenerateSalt(16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(salt) != 16 {
+			t.Fatalf("GenerateSalt(16) returned %d bytes", len(salt))
+		}
+		salts[string(salt)] = true
+	}
+	if len(salts) != 100 {
+		t.Errorf("GenerateSalt returned %d different salts in 100 calls", len(salts))
+	}
+	for _, n := range []int{0, -1} {
+		if _, err := GenerateSalt(n); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateSalt(%d) error = %v, want %v", n, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateSaltedToken(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 16, NumDigits: 2, NumSymbols: 2, AllowUppercase: true}
+	token1, salt1, err := g.GenerateSaltedToken(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, salt2, err := g.GenerateSaltedToken(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token1) != 16 || len(salt1) != SaltSize || len(salt2) != SaltSize {
+		t.Errorf("GenerateSaltedToken = %q, %d bytes of salt, want 16 characters and %d bytes", token1, len(salt1), SaltSize)
+	}
+	if token1 == token2 || bytes.Equal(salt1, salt2) {
+		t.Errorf("two calls returned the same token or salt: %q %x, %q %x", token1, salt1, token2, salt2)
+	}
+	if _, _, err := g.GenerateSaltedToken(GenerateConfig{Length: 2, NumDigits: 3}); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("GenerateSaltedToken(invalid) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+}
+
+func TestGenerateAvoidingPII(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "abc"})
+	params := GenerateConfig{Length: 4, AllowRepeat: true}
+	for range 300 {
+		pwd, err := g.GenerateAvoidingPII([]string{"ABC", "bb"}, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(pwd, "abc") {
+			t.Fatalf("password %q contains the identifier %q", pwd, "abc")
+		}
+	}
+	// Identifiers shorter than 3 characters are ignored
+	short := false
+	for range 300 {
+		pwd, err := g.GenerateAvoidingPII([]string{"bb"}, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		short = short || strings.Contains(pwd, "bb")
+	}
+	if !short {
+		t.Errorf("the identifier %q shorter than 3 characters was avoided", "bb")
+	}
+
+	g = NewGenerator(&GeneratorInput{LowerLetters: "a"})
+	if _, err := g.GenerateAvoidingPII([]string{"aaa"}, GenerateConfig{Length: 3, AllowRepeat: true}); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateAvoidingPII(unavoidable) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+}
+
+func TestGenerateWithFixedChars(t *testing.T) {
+	g := NewGenerator(nil)
+	fixed := map[int]rune{0: 'X', 4: '-', 9: '-', 13: '€'}
+	for range 200 {
+		pwd, err := g.GenerateWithFixedChars(14, fixed, 3, 1, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runes := []rune(pwd)
+		if len(runes) != 14 {
+			t.Fatalf("password %q has %d characters, want 14", pwd, len(runes))
+		}
+		var free []rune
+		for i, r := range runes {
+			if c, ok := fixed[i]; ok {
+				if r != c {
+					t.Fatalf("password %q has %q at position %d, want %q", pwd, r, i, c)
+				}
+				continue
+			}
+			free = append(free, r)
+		}
+		// The quotas only concern the free positions
+		if digits, symbols := countClasses(g, string(free)); digits != 3 || symbols != 1 {
+			t.Fatalf("free characters %q of %q have %d digits and %d symbols, want 3 and 1", string(free), pwd, digits, symbols)
+		}
+	}
+	for _, fixed := range []map[int]rune{{-1: 'a'}, {14: 'a'}} {
+		if _, err := g.GenerateWithFixedChars(14, fixed, 3, 1, true, false); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateWithFixedChars(%v) error = %v, want %v", fixed, err, ErrInvalidArgument)
+		}
+	}
+	// 3 free positions for 2 digits and 2 symbols
+	if _, err := g.GenerateWithFixedChars(5, map[int]rune{2: '-', 3: '-'}, 2, 2, true, false); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("GenerateWithFixedChars(quotas over the free positions) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+}
+
+func TestGenerateBaseN(t *testing.T) {
+	for _, base := range []int{2, 8, 16, 36} {
+		alphabet := "0123456789abcdefghijklmnopqrstuvwxyz"[:base]
+		seen := make(map[rune]bool)
+		for range 100 {
+			s, err := GenerateBaseN(base, 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s) != 20 || strings.Trim(s, alphabet) != "" {
+				t.Fatalf("GenerateBaseN(%d, 20) = %q, want 20 digits of %q", base, s, alphabet)
+			}
+			for _, r := range s {
+				seen[r] = true
+			}
+		}
+		if len(seen) != base {
+			t.Errorf("GenerateBaseN(%d) used %d different digits", base, len(seen))
+		}
+	}
+	for _, args := range [][2]int{{1, 5}, {37, 5}, {16, 0}} {
+		if _, err := GenerateBaseN(args[0], args[1]); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateBaseN(%d, %d) error = %v, want %v", args[0], args[1], err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateManySorted(t *testing.T) {
+	g := NewGenerator(nil)
+	passwords, err := g.GenerateManySorted(50, GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2, AllowUppercase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passwords) != 50 || !slices.IsSorted(passwords) {
+		t.Errorf("GenerateManySorted(50) = %q, want 50 sorted passwords", passwords)
+	}
+	for _, pwd := range passwords {
+		if len(pwd) != 12 {
+			t.Errorf("password %q has %d characters, want 12", pwd, len(pwd))
+		}
+	}
+	if _, err := g.GenerateManySorted(5, GenerateConfig{Length: 2, NumDigits: 3}); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("GenerateManySorted(invalid) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+}
+
+func TestWithRejectNonASCII(t *testing.T) {
+	unicodePool := &GeneratorInput{Symbols: "!€"}
+	g := NewGenerator(unicodePool, WithRejectNonASCII())
+	if err := g.Validate(); !errors.Is(err, ErrNonASCIIPool) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrNonASCIIPool)
+	}
+	if _, err := g.Generate(12, 2, 2, true, true); !errors.Is(err, ErrNonASCIIPool) {
+		t.Errorf("Generate() error = %v, want %v", err, ErrNonASCIIPool)
+	}
+	if _, err := NewGeneratorValidated(unicodePool, WithRejectNonASCII()); !errors.Is(err, ErrNonASCIIPool) {
+		t.Errorf("NewGeneratorValidated() error = %v, want %v", err, ErrNonASCIIPool)
+	}
+
+	// ASCII pools pass, and the behavior is unchanged without the option
+	for _, g := range []*Generator{NewGenerator(nil, WithRejectNonASCII()), NewGenerator(unicodePool)} {
+		if err := g.Validate(); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+		if _, err := g.Generate(12, 2, 2, true, true); err != nil {
+			t.Errorf("Generate() error = %v", err)
+		}
+	}
+}
+
+func TestGenerateHybrid(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, allowUpper := range []bool{false, true} {
+		for range 200 {
+			pwd, err := g.GenerateHybrid(3, 2, 1, allowUpper)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pwd) != 9 {
+				t.Fatalf("GenerateHybrid(3, 2, 1) = %q, want 9 characters", pwd)
+			}
+			// Consonant-vowel syllables, then the digits and the symbols
+			syllables := pwd[:6]
+			if allowUpper {
+				if syllables[0] < 'A' || syllables[0] > 'Z' {
+					t.Fatalf("password %q does not start with an uppercase letter", pwd)
+				}
+				syllables = strings.ToLower(syllables)
+			}
+			for i := 0; i < 6; i += 2 {
+				if !strings.ContainsRune(consonants, rune(syllables[i])) || !strings.ContainsRune(vowels, rune(syllables[i+1])) {
+					t.Fatalf("password %q: %q is not a pronounceable syllable", pwd, syllables[i:i+2])
+				}
+			}
+			if pattern := classPattern(g, pwd); pattern != "LLLLLLDDS" {
+				t.Fatalf("password %q has the classes %s, want LLLLLLDDS", pwd, pattern)
+			}
+		}
+	}
+	for _, counts := range [][3]int{{0, 2, 1}, {3, -1, 1}, {3, 2, -1}} {
+		if _, err := g.GenerateHybrid(counts[0], counts[1], counts[2], true); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateHybrid(%v) error = %v, want %v", counts, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateAlphabetMinEntropy(t *testing.T) {
+	for _, tt := range []struct {
+		alphabet    string
+		bits        float64
+		allowRepeat bool
+		length      int
+	}{
+		{"01", 10, true, 10},
+		{"0123456789abcdef", 128, true, 32},
+		{"0123456789abcdef", 127.5, true, 32},
+		{"abc", 4, true, 3},
+		{"aabb", 2, true, 2},
+		// 16*15 = 240 < 2^10 <= 16*15*14
+		{"0123456789abcdef", 10, false, 3},
+		{"abc", 2.5, false, 2},
+		{"abcd", 4, false, 3},
+	} {
+		for range 20 {
+			s, err := GenerateAlphabetMinEntropy(tt.alphabet, tt.bits, tt.allowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s) != tt.length || strings.Trim(s, tt.alphabet) != "" {
+				t.Fatalf("GenerateAlphabetMinEntropy(%q, %v, %v) = %q, want %d characters of the alphabet", tt.alphabet, tt.bits, tt.allowRepeat, s, tt.length)
+			}
+			if !tt.allowRepeat && distinctCount(s) != len(s) {
+				t.Fatalf("GenerateAlphabetMinEntropy(%q, %v, false) = %q repeats a character", tt.alphabet, tt.bits, s)
+			}
+		}
+	}
+
+	// log2(3!) < 3 bits without repeat, and a single character brings no entropy
+	for _, tt := range []struct {
+		alphabet    string
+		allowRepeat bool
+	}{{"abc", false}, {"a", true}, {"aaa", true}} {
+		if _, err := GenerateAlphabetMinEntropy(tt.alphabet, 3, tt.allowRepeat); !errors.Is(err, ErrEntropyUnreachable) {
+			t.Errorf("GenerateAlphabetMinEntropy(%q, 3, %v) error = %v, want %v", tt.alphabet, tt.allowRepeat, err, ErrEntropyUnreachable)
+		}
+	}
+	for _, bits := range []float64{0, -1, math.NaN()} {
+		if _, err := GenerateAlphabetMinEntropy("abc", bits, true); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateAlphabetMinEntropy(%v bits) error = %v, want %v", bits, err, ErrInvalidArgument)
+		}
+	}
+	if _, err := GenerateAlphabetMinEntropy("", 8, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateAlphabetMinEntropy(empty) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGeneratePair(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab"})
+	params := GenerateConfig{AllowRepeat: true}
+	shared := func(a, b string) int {
+		n := 0
+		for i := range min(len(a), len(b)) {
+			if a[i] == b[i] {
+				n++
+			}
+		}
+		return n
+	}
+	for _, maxShared := range []int{0, 1, 2, 6} {
+		for range 100 {
+			a, b, err := g.GeneratePair(6, maxShared, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(a) != 6 || len(b) != 6 || shared(a, b) > maxShared {
+				t.Fatalf("GeneratePair(6, %d) = %q, %q sharing %d positions", maxShared, a, b, shared(a, b))
+			}
+		}
+	}
+
+	// A single character is shared at every position
+	g = NewGenerator(&GeneratorInput{LowerLetters: "a"})
+	if _, _, err := g.GeneratePair(3, 2, params); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GeneratePair(3 positions, 2 shared) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+	if a, b, err := g.GeneratePair(3, 3, params); err != nil || a != "aaa" || b != "aaa" {
+		t.Errorf("GeneratePair(3 positions, 3 shared) = %q, %q, %v, want %q, %q, nil", a, b, err, "aaa", "aaa")
+	}
+	if _, _, err := g.GeneratePair(3, -1, params); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GeneratePair(-1 shared) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGenerateUntil(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 8, NumDigits: 1, AllowRepeat: true}
+	for range 100 {
+		pwd, err := g.GenerateUntil(func(s string) bool { return strings.ContainsRune(s, 'z') }, 0, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.ContainsRune(pwd, 'z') {
+			t.Fatalf("password %q does not contain 'z'", pwd)
+		}
+	}
+
+	calls := 0
+	if _, err := g.GenerateUntil(func(string) bool { calls++; return false }, 5, params); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateUntil(never accepted) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+	if calls != 5 {
+		t.Errorf("accept function called %d times, want 5", calls)
+	}
+	calls = 0
+	g = NewGenerator(nil, WithMaxAttempts(3))
+	g.GenerateUntil(func(string) bool { calls++; return false }, 0, params)
+	if calls != 3 {
+		t.Errorf("accept function called %d times without maxAttempts, want the 3 of the generator", calls)
+	}
+}
+
+func TestGenerateChunkedMemorable(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, tt := range []struct {
+		separators, want []string
+	}{
+		{nil, []string{"-", ".", "_"}},
+		{[]string{"+", "::"}, []string{"+", "::"}},
+	} {
+		used := make(map[string]bool)
+		for range 200 {
+			pwd, err := g.GenerateChunkedMemorable(4, 5, tt.separators)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Split the chunks of letters from the separators
+			chunks := strings.FieldsFunc(pwd, func(r rune) bool { return !strings.ContainsRune(g.lowerLetters, r) })
+			separators := strings.FieldsFunc(pwd, func(r rune) bool { return strings.ContainsRune(g.lowerLetters, r) })
+			if len(chunks) != 4 || len(separators) != 3 {
+				t.Fatalf("password %q has %d chunks and %d separators, want 4 and 3", pwd, len(chunks), len(separators))
+			}
+			for _, chunk := range chunks {
+				if len(chunk) != 5 {
+					t.Fatalf("password %q has the chunk %q, want 5 letters", pwd, chunk)
+				}
+			}
+			for _, sep := range separators {
+				if !slices.Contains(tt.want, sep) {
+					t.Fatalf("password %q has the separator %q, want one of %q", pwd, sep, tt.want)
+				}
+				used[sep] = true
+			}
+		}
+		if len(used) != len(tt.want) {
+			t.Errorf("separators used: %v, want all of %q", used, tt.want)
+		}
+	}
+	if pwd, err := g.GenerateChunkedMemorable(1, 3, nil); err != nil || len(pwd) != 3 {
+		t.Errorf("GenerateChunkedMemorable(1, 3) = %q, %v, want a single chunk", pwd, err)
+	}
+	for _, args := range [][2]int{{0, 5}, {4, 0}} {
+		if _, err := g.GenerateChunkedMemorable(args[0], args[1], nil); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateChunkedMemorable(%d, %d) error = %v, want %v", args[0], args[1], err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateQRFriendly(t *testing.T) {
+	g := NewGenerator(nil)
+	if len(QRAlphanumeric) != 45 {
+		t.Fatalf("QRAlphanumeric has %d characters, want 45", len(QRAlphanumeric))
+	}
+	for _, allowRepeat := range []bool{true, false} {
+		for range 100 {
+			pwd, err := g.GenerateQRFriendly(20, allowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pwd) != 20 || strings.Trim(pwd, QRAlphanumeric) != "" {
+				t.Fatalf("GenerateQRFriendly(20, %v) = %q, want 20 QR alphanumeric characters", allowRepeat, pwd)
+			}
+			if !allowRepeat && distinctCount(pwd) != 20 {
+				t.Fatalf("GenerateQRFriendly(20, false) = %q repeats a character", pwd)
+			}
+		}
+	}
+	// The whole set without repeat
+	if pwd, err := g.GenerateQRFriendly(45, false); err != nil || distinctCount(pwd) != 45 {
+		t.Errorf("GenerateQRFriendly(45, false) = %q, %v, want the 45 characters", pwd, err)
+	}
+	if _, err := g.GenerateQRFriendly(46, false); !errors.Is(err, ErrLengthExceedsAvailable) {
+		t.Errorf("GenerateQRFriendly(46, false) error = %v, want %v", err, ErrLengthExceedsAvailable)
+	}
+}
+
+func TestWithExcludeRange(t *testing.T) {
+	// The range applies to the sets given by the options after it
+	g := NewGenerator(nil, WithExcludeRange('#', '&'), WithSymbols("!#$%&*+-="), WithExcludeRange('0', '4'))
+	if g.symbols != "!*+-=" || g.digits != "56789" {
+		t.Errorf("symbols, digits = %q, %q, want %q, %q", g.symbols, g.digits, "!*+-=", "56789")
+	}
+	if n := g.MaxUniqueSymbols(); n != 5 {
+		t.Errorf("MaxUniqueSymbols() = %d, want 5", n)
+	}
+	for range 200 {
+		pwd, err := g.Generate(12, 5, 5, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsAny(pwd, "#$%&01234") {
+			t.Fatalf("password %q contains an excluded character", pwd)
+		}
+	}
+	if _, err := g.Generate(12, 0, 6, true, false); !errors.Is(err, ErrSymbolsExceedsAvailable) {
+		t.Errorf("Generate(6 of the 5 symbols) error = %v, want %v", err, ErrSymbolsExceedsAvailable)
+	}
+	if _, err := g.Generate(12, 6, 0, true, false); !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("Generate(6 of the 5 digits) error = %v, want %v", err, ErrDigitsExceedsAvailable)
+	}
+
+	// A whole set removed
+	if _, err := NewGeneratorValidated(nil, WithExcludeRange('0', '9')); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("NewGeneratorValidated(no digit left) error = %v, want %v", err, ErrEmptyPool)
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	const base32Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	for n, want := range map[int]int{1: 1, 10: 10, 20: 20, 32: 32, 0: DefaultTOTPSecretSize, -5: DefaultTOTPSecretSize} {
+		secret, err := GenerateTOTPSecret(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Trim(secret, base32Chars) != "" {
+			t.Errorf("GenerateTOTPSecret(%d) = %q, want only base32 characters without padding", n, secret)
+		}
+		b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+		if err != nil {
+			t.Fatalf("decoding secret %q: %v", secret, err)
+		}
+		if len(b) != want {
+			t.Errorf("GenerateTOTPSecret(%d) decodes to %d bytes, want %d", n, len(b), want)
+		}
+	}
+}
+
+func TestGenerateWithTimeout(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 8, NumDigits: 1, AllowRepeat: true}
+	start := time.Now()
+	if _, err := g.GenerateWithTimeout(50*time.Millisecond, func(string) bool { return false }, params); !errors.Is(err, ErrTimeout) {
+		t.Errorf("GenerateWithTimeout(never accepted) error = %v, want %v", err, ErrTimeout)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("GenerateWithTimeout(50ms) returned after %v", elapsed)
+	}
+
+	pwd, err := g.GenerateWithTimeout(time.Second, func(s string) bool { return strings.ContainsRune(s, 'z') }, params)
+	if err != nil || !strings.ContainsRune(pwd, 'z') {
+		t.Errorf("GenerateWithTimeout(contains 'z') = %q, %v", pwd, err)
+	}
+	if _, err := g.GenerateWithTimeout(0, func(string) bool { return true }, params); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateWithTimeout(0) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestWithAllowedEdgeClasses(t *testing.T) {
+	class := func(g *Generator, r rune) byte {
+		switch {
+		case strings.ContainsRune(g.lowerLetters, r):
+			return 'L'
+		case strings.ContainsRune(g.upperLetters, r):
+			return 'U'
+		case strings.ContainsRune(g.digits, r):
+			return 'D'
+		}
+		return 'S'
+	}
+	for _, tt := range []struct{ first, last string }{{"D", "S"}, {"U", ""}, {"", "LD"}, {"S", "S"}} {
+		g := NewGenerator(nil, WithAllowedFirstClasses(tt.first), WithAllowedLastClasses(tt.last))
+		for range 300 {
+			pwd, err := g.Generate(8, 2, 2, true, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			runes := []rune(pwd)
+			first, last := class(g, runes[0]), class(g, runes[len(runes)-1])
+			if tt.first != "" && !strings.ContainsRune(tt.first, rune(first)) || tt.last != "" && !strings.ContainsRune(tt.last, rune(last)) {
+				t.Fatalf("password %q starts with %c and ends with %c, want %q and %q", pwd, first, last, tt.first, tt.last)
+			}
+			if digits, symbols := countClasses(g, pwd); digits != 2 || symbols != 2 {
+				t.Fatalf("password %q has %d digits and %d symbols, want 2 and 2", pwd, digits, symbols)
+			}
+		}
+	}
+
+	// No symbol to start the password, and a single digit for both edges
+	g := NewGenerator(nil, WithAllowedFirstClasses("S"))
+	if _, err := g.Generate(8, 2, 0, true, false); !errors.Is(err, ErrEdgeClassNotAllowed) {
+		t.Errorf("Generate(no symbol, first S) error = %v, want %v", err, ErrEdgeClassNotAllowed)
+	}
+	g = NewGenerator(nil, WithAllowedFirstClasses("D"), WithAllowedLastClasses("D"))
+	if _, err := g.Generate(8, 1, 0, true, false); !errors.Is(err, ErrEdgeClassNotAllowed) {
+		t.Errorf("Generate(1 digit, first and last D) error = %v, want %v", err, ErrEdgeClassNotAllowed)
+	}
+}
+
+// replacerNormalizer is a normalization form limited to a few characters.
+type replacerNormalizer struct{ *strings.Replacer }
+
+func (n replacerNormalizer) String(s string) string {
+	return n.Replace(s)
+}
+
+func TestWithNormalization(t *testing.T) {
+	nfc := replacerNormalizer{strings.NewReplacer("e\u0301", "\u00e9", "\u212b", "\u00c5")}
+	nfd := replacerNormalizer{strings.NewReplacer("\u00e9", "e\u0301")}
+
+	// The Angstrom sign and the A with ring above are merged
+	g := NewGenerator(&GeneratorInput{LowerLetters: "e", Symbols: "\u212b\u00c5\u0301"}, WithNormalization(nfc))
+	if g.symbols != "\u00c5\u0301" {
+		t.Errorf("symbols = %+q, want %+q", g.symbols, "\u00c5\u0301")
+	}
+	if _, err := g.Generate(3, 0, 3, false, false); !errors.Is(err, ErrSymbolsExceedsAvailable) {
+		t.Errorf("Generate(3 of the 2 symbols) error = %v, want %v", err, ErrSymbolsExceedsAvailable)
+	}
+	// A letter followed by the combining accent is composed
+	composed := false
+	for range 200 {
+		pwd, err := g.Generate(6, 0, 3, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(pwd, "e\u0301") || strings.ContainsRune(pwd, '\u212b') {
+			t.Fatalf("password %+q is not in the composed form", pwd)
+		}
+		composed = composed || strings.ContainsRune(pwd, '\u00e9')
+	}
+	if !composed {
+		t.Error("no password has a composed letter")
+	}
+
+	// A decomposed character stays one character of the set
+	g = NewGenerator(&GeneratorInput{LowerLetters: "\u00e9a"}, WithNormalization(nfd))
+	for range 50 {
+		pwd, err := g.Generate(2, 0, 0, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pwd != "e\u0301a" && pwd != "ae\u0301" {
+			t.Fatalf("password %+q is not the 2 letters in the decomposed form", pwd)
+		}
+	}
+	if _, err := g.Generate(3, 0, 0, false, false); !errors.Is(err, ErrLettersExceedsAvailable) {
+		t.Errorf("Generate(3 of the 2 letters) error = %v, want %v", err, ErrLettersExceedsAvailable)
+	}
+
+	// Without the option, the passwords are left as drawn
+	g = NewGenerator(&GeneratorInput{LowerLetters: "e", Symbols: "\u0301"})
+	if pwd, err := g.Generate(2, 0, 1, false, false); err != nil || pwd != "e\u0301" && pwd != "\u0301e" {
+		t.Errorf("Generate() = %+q, %v, want the letter and the accent", pwd, err)
+	}
+}
+
+func TestGenerateMaskWithMinimums(t *testing.T) {
+	g := NewGenerator(nil)
+	for range 300 {
+		pwd, err := g.GenerateMaskWithMinimums("uaaaaaad", map[rune]int{'d': 3, 's': 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pwd) != 8 || !strings.ContainsRune(g.upperLetters, rune(pwd[0])) || !strings.ContainsRune(g.digits, rune(pwd[7])) {
+			t.Fatalf("password %q does not follow the mask %q", pwd, "uaaaaaad")
+		}
+		if digits, symbols := countClasses(g, pwd); digits < 3 || symbols < 2 {
+			t.Fatalf("password %q has %d digits and %d symbols, want at least 3 and 2", pwd, digits, symbols)
+		}
+	}
+	// The minimums fill all the a positions
+	for range 100 {
+		pwd, err := g.GenerateMaskWithMinimums("daas", map[rune]int{'d': 2, 's': 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pattern := classPattern(g, pwd); pattern != "DDSS" && pattern != "DSDS" {
+			t.Fatalf("password %q has the classes %s, want 2 digits then 2 symbols", pwd, pattern)
+		}
+	}
+
+	if _, err := g.GenerateMaskWithMinimums("ldaa", map[rune]int{'s': 3}); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateMaskWithMinimums(3 symbols in 2 a positions) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+	for _, tt := range []struct {
+		mask       string
+		minClasses map[rune]int
+	}{{"lxa", nil}, {"laa", map[rune]int{'x': 1}}, {"laa", map[rune]int{'d': -1}}} {
+		if _, err := g.GenerateMaskWithMinimums(tt.mask, tt.minClasses); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateMaskWithMinimums(%q, %v) error = %v, want %v", tt.mask, tt.minClasses, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateGaussianLengths(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{NumDigits: 2, NumSymbols: 1, AllowUppercase: true, AllowRepeat: true}
+	passwords, err := g.GenerateGaussianLengths(2000, 14, 3, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passwords) != 2000 {
+		t.Fatalf("GenerateGaussianLengths(2000) returned %d passwords", len(passwords))
+	}
+	var sum, sumSquares float64
+	for _, pwd := range passwords {
+		n := len(pwd)
+		if n < 3 || n > 26 {
+			t.Fatalf("password %q has %d characters, want between 3 and 26", pwd, n)
+		}
+		if digits, symbols := countClasses(g, pwd); digits != 2 || symbols != 1 {
+			t.Fatalf("password %q has %d digits and %d symbols, want 2 and 1", pwd, digits, symbols)
+		}
+		sum += float64(n)
+		sumSquares += float64(n * n)
+	}
+	mean := sum / 2000
+	stdDev := math.Sqrt(sumSquares/2000 - mean*mean)
+	if math.Abs(mean-14) > 0.3 || math.Abs(stdDev-3) > 0.3 {
+		t.Errorf("lengths have a mean of %v and a standard deviation of %v, want about 14 and 3", mean, stdDev)
+	}
+
+	// The lengths are clamped to hold the digits and the symbols
+	passwords, err = g.GenerateGaussianLengths(200, 2, 5, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pwd := range passwords {
+		if len(pwd) < 3 || len(pwd) > 22 {
+			t.Fatalf("password %q has %d characters, want between 3 and 22", pwd, len(pwd))
+		}
+	}
+	if passwords, err := g.GenerateGaussianLengths(50, 10, 0, params); err != nil || slices.ContainsFunc(passwords, func(s string) bool { return len(s) != 10 }) {
+		t.Errorf("GenerateGaussianLengths(stdDev 0) = %q, %v, want 10 characters each", passwords, err)
+	}
+	for _, args := range [][3]float64{{-1, 10, 2}, {5, 0, 2}, {5, 10, -1}, {5, math.NaN(), 2}} {
+		if _, err := g.GenerateGaussianLengths(int(args[0]), args[1], args[2], params); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateGaussianLengths(%v) error = %v, want %v", args, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateSplit(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, tt := range []struct {
+		length, parts int
+		sizes         []int
+	}{
+		{12, 1, []int{12}},
+		{12, 3, []int{4, 4, 4}},
+		{14, 4, []int{4, 4, 3, 3}},
+		{5, 5, []int{1, 1, 1, 1, 1}},
+	} {
+		shares, password, err := g.GenerateSplit(tt.length, tt.parts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if utf8.RuneCountInString(password) != tt.length || strings.Join(shares, "") != password {
+			t.Errorf("GenerateSplit(%d, %d) = %q, %q: the shares do not rejoin to the password", tt.length, tt.parts, shares, password)
+		}
+		sizes := make([]int, len(shares))
+		for i, share := range shares {
+			sizes[i] = utf8.RuneCountInString(share)
+		}
+		if !slices.Equal(sizes, tt.sizes) {
+			t.Errorf("GenerateSplit(%d, %d) shares have %v characters, want %v", tt.length, tt.parts, sizes, tt.sizes)
+		}
+	}
+	for _, args := range [][2]int{{12, 0}, {4, 5}} {
+		if _, _, err := g.GenerateSplit(args[0], args[1]); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateSplit(%d, %d) error = %v, want %v", args[0], args[1], err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestGenerateWithMinLowercase(t *testing.T) {
+	g := NewGenerator(nil)
+	upper := false
+	for _, allowRepeat := range []bool{true, false} {
+		for range 300 {
+			pwd, err := g.GenerateWithMinLowercase(12, 6, 2, 2, allowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lower := 0
+			for _, r := range pwd {
+				if strings.ContainsRune(g.lowerLetters, r) {
+					lower++
+				}
+			}
+			digits, symbols := countClasses(g, pwd)
+			if len(pwd) != 12 || lower < 6 || digits != 2 || symbols != 2 {
+				t.Fatalf("password %q has %d lowercase letters, %d digits and %d symbols, want at least 6, 2 and 2", pwd, lower, digits, symbols)
+			}
+			if !allowRepeat && distinctCount(pwd) != 12 {
+				t.Fatalf("password %q repeats a character", pwd)
+			}
+			upper = upper || strings.ContainsAny(pwd, g.upperLetters)
+		}
+	}
+	if !upper {
+		t.Error("no password has an uppercase letter")
+	}
+	// Only lowercase letters
+	pwd, err := g.GenerateWithMinLowercase(8, 8, 0, 0, false)
+	if err != nil || strings.Trim(pwd, g.lowerLetters) != "" {
+		t.Errorf("GenerateWithMinLowercase(8, 8) = %q, %v, want 8 lowercase letters", pwd, err)
+	}
+
+	for _, args := range [][4]int{{12, 9, 2, 2}, {12, -1, 2, 2}} {
+		if _, err := g.GenerateWithMinLowercase(args[0], args[1], args[2], args[3], true); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateWithMinLowercase(%v) error = %v, want %v", args, err, ErrInvalidArgument)
+		}
+	}
+	g = NewGenerator(&GeneratorInput{LowerLetters: "abc"})
+	if _, err := g.GenerateWithMinLowercase(8, 4, 0, 0, false); !errors.Is(err, ErrLettersExceedsAvailable) {
+		t.Errorf("GenerateWithMinLowercase(4 of the 3 lowercase letters) error = %v, want %v", err, ErrLettersExceedsAvailable)
+	}
+}
+
+func TestGenerateMarkov(t *testing.T) {
+	g := NewGenerator(nil)
+	transitions := map[rune]map[rune]float64{
+		'a': {'b': 1, 'c': 3, 'x': 0},
+		'b': {'a': 1},
+		'c': {'a': 1, 'b': 1},
+	}
+	fromA, toC := 0, 0
+	for range 300 {
+		pwd, err := g.GenerateMarkov(12, transitions, 'a')
+		if err != nil {
+			t.Fatal(err)
+		}
+		runes := []rune(pwd)
+		if len(runes) != 12 || runes[0] != 'a' {
+			t.Fatalf("GenerateMarkov(12, 'a') = %q, want 12 characters starting with 'a'", pwd)
+		}
+		for i := 1; i < len(runes); i++ {
+			if !(transitions[runes[i-1]][runes[i]] > 0) {
+				t.Fatalf("password %q has the transition %q -> %q", pwd, runes[i-1], runes[i])
+			}
+			if runes[i-1] == 'a' {
+				fromA++
+				if runes[i] == 'c' {
+					toC++
+				}
+			}
+		}
+	}
+	if ratio := float64(toC) / float64(fromA); math.Abs(ratio-0.75) > 0.05 {
+		t.Errorf("'a' -> 'c' drawn with a frequency of %v, want about 0.75", ratio)
+	}
+	if pwd, err := g.GenerateMarkov(1, nil, 'z'); err != nil || pwd != "z" {
+		t.Errorf("GenerateMarkov(1, 'z') = %q, %v, want %q", pwd, err, "z")
+	}
+
+	// 'd' has no outgoing transition
+	if _, err := g.GenerateMarkov(5, map[rune]map[rune]float64{'a': {'d': 1}}, 'a'); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateMarkov(dead end) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+	for _, weight := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if _, err := g.GenerateMarkov(5, map[rune]map[rune]float64{'a': {'a': weight}}, 'a'); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateMarkov(weight %v) error = %v, want %v", weight, err, ErrInvalidArgument)
+		}
+	}
+	if _, err := g.GenerateMarkov(0, transitions, 'a'); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateMarkov(0) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGenerateStrictDigits(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, numDigits := range []int{2, 5, 10} {
+		for range 100 {
+			pwd, err := g.GenerateStrictDigits(14, numDigits, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var digits []rune
+			for _, r := range pwd {
+				if strings.ContainsRune(g.digits, r) {
+					digits = append(digits, r)
+				}
+			}
+			if len(pwd) != 14 || len(digits) != numDigits || distinctCount(string(digits)) != numDigits {
+				t.Fatalf("password %q has the digits %q, want %d distinct digits", pwd, string(digits), numDigits)
+			}
+			for i := 1; i < len(digits); i++ {
+				if d := digits[i] - digits[i-1]; d == 1 || d == -1 {
+					t.Fatalf("password %q has the sequential digits %q", pwd, string(digits[i-1:i+1]))
+				}
+			}
+		}
+	}
+	if _, err := g.GenerateStrictDigits(14, 11, true); !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("GenerateStrictDigits(11 digits) error = %v, want %v", err, ErrDigitsExceedsAvailable)
+	}
+	// 2 distinct digits among 0 and 1 are always sequential
+	g = NewGenerator(&GeneratorInput{Digits: "01"})
+	if _, err := g.GenerateStrictDigits(6, 2, true); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("GenerateStrictDigits(2 of the digits 01) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+}
+
+func TestGenerateCSV(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{Symbols: `,"`})
+	entries := []struct{ Name, Username string }{{"Bank, Inc.", "alice"}, {"Mail", "bob@example.com"}}
+	var b bytes.Buffer
+	if err := g.GenerateCSV(&b, entries, GenerateConfig{Length: 10, NumDigits: 2, NumSymbols: 2, AllowUppercase: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The passwords contain a comma or a double quote, so they are quoted
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != "name,username,password" {
+		t.Fatalf("CSV = %q, want a header and 2 rows", b.String())
+	}
+	for _, line := range lines[1:] {
+		if !strings.HasSuffix(line, `"`) {
+			t.Errorf("row %q does not end with a quoted password", line)
+		}
+	}
+
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, entry := range entries {
+		record := records[i+1]
+		if record[0] != entry.Name || record[1] != entry.Username {
+			t.Errorf("row %d = %q, want %q and %q", i+1, record, entry.Name, entry.Username)
+		}
+		if digits, symbols := countClasses(g, record[2]); len(record[2]) != 10 || digits != 2 || symbols != 2 {
+			t.Errorf("row %d has the password %q, want 10 characters with 2 digits and 2 symbols", i+1, record[2])
+		}
+	}
+
+	if err := g.GenerateCSV(&b, entries, GenerateConfig{Length: 2, NumDigits: 3}); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("GenerateCSV(invalid) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+}
+
+func TestGenerateWithConfig(t *testing.T) {
+	for _, cfg := range []GenerateConfig{
+		{Length: 12, NumDigits: 3, NumSymbols: 2, AllowUppercase: true},
+		{Length: 8, NumDigits: 0, NumSymbols: 5, AllowRepeat: true},
+		{Length: 6, NumDigits: 6},
+	} {
+		// Both forms draw the same characters from the same source
+		positional, named := NewSeededGenerator(nil, 252), NewSeededGenerator(nil, 252)
+		for range 50 {
+			a, err := positional.Generate(cfg.Length, cfg.NumDigits, cfg.NumSymbols, cfg.AllowUppercase, cfg.AllowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := named.GenerateWithConfig(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a != b {
+				t.Fatalf("Generate(%+v) = %q, GenerateWithConfig = %q", cfg, a, b)
+			}
+			if digits, symbols := countClasses(positional, b); len(b) != cfg.Length || digits != cfg.NumDigits || symbols != cfg.NumSymbols {
+				t.Fatalf("GenerateWithConfig(%+v) = %q with %d digits and %d symbols", cfg, b, digits, symbols)
+			}
+		}
+	}
+	g := NewGenerator(nil)
+	_, err1 := g.Generate(4, 3, 3, true, true)
+	_, err2 := g.GenerateWithConfig(GenerateConfig{Length: 4, NumDigits: 3, NumSymbols: 3, AllowUppercase: true, AllowRepeat: true})
+	if !errors.Is(err1, ErrExceedsTotalLength) || !errors.Is(err2, ErrExceedsTotalLength) {
+		t.Errorf("errors = %v, %v, want %v", err1, err2, ErrExceedsTotalLength)
+	}
+}
+
+func TestWithAvoidAnagrams(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "abcdt", UpperLetters: "ABCDT"}, WithAvoidAnagrams([]string{"Cat", "bb", ""}))
+	for range 500 {
+		pwd, err := g.Generate(5, 0, 0, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lower := strings.ToLower(pwd)
+		if strings.ContainsRune(lower, 'c') && strings.ContainsRune(lower, 'a') && strings.ContainsRune(lower, 't') {
+			t.Fatalf("password %q contains an anagram of %q", pwd, "cat")
+		}
+		if strings.Count(lower, "b") > 1 {
+			t.Fatalf("password %q contains an anagram of %q", pwd, "bb")
+		}
+	}
+
+	// Every password is made of the letters of the word
+	g = NewGenerator(&GeneratorInput{LowerLetters: "act"}, WithAvoidAnagrams([]string{"tac"}))
+	if _, err := g.Generate(3, 0, 0, false, false); !errors.Is(err, ErrCannotSatisfy) {
+		t.Errorf("Generate(only anagrams) error = %v, want %v", err, ErrCannotSatisfy)
+	}
+}
+
+func TestGenerateUnicodeQuotas(t *testing.T) {
+	g := NewGenerator(&GeneratorInput{LowerLetters: "abcαβγ", UpperLetters: "ABCΔΣ", Digits: "0123", Symbols: "!?"})
+	count := func(pwd string, table *unicode.RangeTable) int {
+		n := 0
+		for _, r := range pwd {
+			if unicode.Is(table, r) {
+				n++
+			}
+		}
+		return n
+	}
+	quotas := map[*unicode.RangeTable]int{unicode.Latin: 3, unicode.Greek: 4}
+	for _, allowRepeat := range []bool{true, false} {
+		for range 200 {
+			pwd, err := g.GenerateUnicodeQuotas(10, quotas, allowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if utf8.RuneCountInString(pwd) != 10 || count(pwd, unicode.Latin) < 3 || count(pwd, unicode.Greek) < 4 {
+				t.Fatalf("password %q does not have 10 characters with 3 Latin and 4 Greek ones", pwd)
+			}
+			if !allowRepeat && distinctCount(pwd) != 10 {
+				t.Fatalf("password %q repeats a character", pwd)
+			}
+		}
+	}
+	// The Greek letters are used up
+	if pwd, err := g.GenerateUnicodeQuotas(5, map[*unicode.RangeTable]int{unicode.Greek: 5}, false); err != nil || count(pwd, unicode.Greek) != 5 {
+		t.Errorf("GenerateUnicodeQuotas(the 5 Greek letters) = %q, %v", pwd, err)
+	}
+
+	for _, quotas := range []map[*unicode.RangeTable]int{{unicode.Greek: 6}, {unicode.Cyrillic: 1}} {
+		if _, err := g.GenerateUnicodeQuotas(10, quotas, false); !errors.Is(err, ErrCannotSatisfy) {
+			t.Errorf("GenerateUnicodeQuotas(%v) error = %v, want %v", quotas, err, ErrCannotSatisfy)
+		}
+	}
+	for _, quotas := range []map[*unicode.RangeTable]int{{unicode.Greek: -1}, {unicode.Greek: 3, unicode.Latin: 8}} {
+		if _, err := g.GenerateUnicodeQuotas(10, quotas, true); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateUnicodeQuotas(%v) error = %v, want %v", quotas, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestPasswordBuilder(t *testing.T) {
+	b, err := NewPasswordBuilder(nil, GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := b.gen
+
+	// Invalid settings are rejected and leave the parameters unchanged
+	if err := b.SetLength(3); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("SetLength(3) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+	if err := b.SetDigits(11); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("SetDigits(11 of 12 characters with 2 symbols) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+	if err := b.SetSymbols(-1); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("SetSymbols(-1) error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if cfg := b.Config(); cfg != (GenerateConfig{Length: 12, NumDigits: 2, NumSymbols: 2}) {
+		t.Errorf("Config() = %+v after invalid settings", cfg)
+	}
+
+	for _, set := range []func() error{
+		func() error { return b.SetLength(16) },
+		func() error { return b.SetDigits(4) },
+		func() error { return b.SetSymbols(3) },
+		b.ToggleUpper,
+		b.ToggleRepeat,
+	} {
+		if err := set(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg := b.Config(); cfg != (GenerateConfig{Length: 16, NumDigits: 4, NumSymbols: 3, AllowUppercase: true, AllowRepeat: true}) {
+		t.Errorf("Config() = %+v", cfg)
+	}
+	// 11 digits are possible with repeats only
+	if err := b.SetDigits(11); err != nil {
+		t.Errorf("SetDigits(11 with repeats) error = %v", err)
+	}
+	if err := b.ToggleRepeat(); !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("ToggleRepeat(11 digits) error = %v, want %v", err, ErrDigitsExceedsAvailable)
+	}
+	if err := b.SetDigits(4); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build returns the last preview, or a new password once the parameters changed
+	preview, err := b.Preview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pwd, err := b.Build(); err != nil || pwd != preview {
+		t.Errorf("Build() = %q, %v, want the preview %q", pwd, err, preview)
+	}
+	if err := b.SetLength(20); err != nil {
+		t.Fatal(err)
+	}
+	pwd, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digits, symbols := countClasses(g, pwd); len(pwd) != 20 || digits != 4 || symbols != 3 {
+		t.Errorf("Build() = %q, want 20 characters with 4 digits and 3 symbols", pwd)
+	}
+
+	if _, err := NewPasswordBuilder(nil, GenerateConfig{Length: 2, NumDigits: 3}); !errors.Is(err, ErrExceedsTotalLength) {
+		t.Errorf("NewPasswordBuilder(invalid) error = %v, want %v", err, ErrExceedsTotalLength)
+	}
+}
+
+func TestWithEntropyModel(t *testing.T) {
+	fixed := func(bits float64) func(string, *Generator) float64 {
+		return func(string, *Generator) float64 { return bits }
+	}
+	params := GenerateConfig{Length: 4, NumDigits: 1, AllowRepeat: true}
+
+	// The reports use the model
+	g := NewGenerator(nil, WithEntropyModel(fixed(42)))
+	if _, bits, err := g.GenerateWithEntropy(params); err != nil || bits != 42 {
+		t.Errorf("GenerateWithEntropy() bits = %v, %v, want 42", bits, err)
+	}
+	if result, err := g.GenerateResult(params); err != nil || result.EntropyBits != 42 {
+		t.Errorf("GenerateResult() EntropyBits = %v, %v, want 42", result.EntropyBits, err)
+	}
+
+	// The guard uses the model instead of the size of the sets
+	if _, err := NewGenerator(nil, WithMinEntropy(100)).GenerateWithConfig(params); !errors.Is(err, ErrInsufficientEntropy) {
+		t.Errorf("GenerateWithConfig(default model) error = %v, want %v", err, ErrInsufficientEntropy)
+	}
+	if _, err := NewGenerator(nil, WithMinEntropy(100), WithEntropyModel(fixed(200))).GenerateWithConfig(params); err != nil {
+		t.Errorf("GenerateWithConfig(model above the minimum) error = %v", err)
+	}
+	if _, err := NewGenerator(nil, WithMinEntropy(10), WithEntropyModel(fixed(5))).GenerateWithConfig(GenerateConfig{Length: 20, AllowUppercase: true}); !errors.Is(err, ErrInsufficientEntropy) {
+		t.Errorf("GenerateWithConfig(model below the minimum) error = %v, want %v", err, ErrInsufficientEntropy)
+	}
+
+	// The passwords estimated below the minimum are drawn again
+	g = NewGenerator(nil, WithMinEntropy(50), WithEntropyModel(func(password string, _ *Generator) float64 {
+		if strings.ContainsRune(password, 'z') {
+			return 100
+		}
+		return 0
+	}))
+	for range 50 {
+		pwd, err := g.GenerateWithConfig(GenerateConfig{Length: 8, AllowRepeat: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.ContainsRune(pwd, 'z') {
+			t.Fatalf("password %q estimated below the minimum entropy", pwd)
+		}
+	}
+}
+
+func TestMaxUniqueLength(t *testing.T) {
+	g := NewGenerator(nil)
+	letters, digits, symbols := utf8.RuneCountInString(LowerLetters), utf8.RuneCountInString(Digits), utf8.RuneCountInString(Symbols)
+	if n := g.MaxUniqueLength(false); n != letters+digits+symbols {
+		t.Errorf("MaxUniqueLength(false) = %d, want %d", n, letters+digits+symbols)
+	}
+	if n := g.MaxUniqueLength(true); n != 2*letters+digits+symbols {
+		t.Errorf("MaxUniqueLength(true) = %d, want %d", n, 2*letters+digits+symbols)
+	}
+	if n := g.MaxUniqueLetters(true); n != 2*letters {
+		t.Errorf("MaxUniqueLetters(true) = %d, want %d", n, 2*letters)
+	}
+	if n := g.MaxUniqueDigits(); n != digits {
+		t.Errorf("MaxUniqueDigits() = %d, want %d", n, digits)
+	}
+
+	// The maximal length can be generated without repeat, not one more character
+	for _, allowUpper := range []bool{false, true} {
+		n := g.MaxUniqueLength(allowUpper)
+		pwd, err := g.Generate(n, digits, symbols, allowUpper, false)
+		if err != nil || distinctCount(pwd) != n {
+			t.Errorf("Generate(%d, upper %v) = %q, %v, want %d distinct characters", n, allowUpper, pwd, err, n)
+		}
+		if _, err := g.Generate(n+1, digits, symbols, allowUpper, false); !errors.Is(err, ErrLettersExceedsAvailable) {
+			t.Errorf("Generate(%d, upper %v) error = %v, want %v", n+1, allowUpper, err, ErrLettersExceedsAvailable)
+		}
+	}
+
+	// The letters are counted once when forced to lowercase
+	if n := NewGenerator(nil, WithForceLowercase()).MaxUniqueLength(true); n != letters+digits+symbols {
+		t.Errorf("MaxUniqueLength(true) with forced lowercase = %d, want %d", n, letters+digits+symbols)
+	}
+}
+
+func TestPolicyDiff(t *testing.T) {
+	g := NewGenerator(nil)
+	policy := &Policy{MinLength: 8, MaxLength: 12, MinLower: 2, MinUpper: 1, MinDigits: 3, MinSymbols: 2}
+	for _, tt := range []struct {
+		password string
+		want     []string
+	}{
+		{"abc1", []string{"too short by 4", "missing uppercase letter", "needs 2 more digits", "needs 2 more symbols"}},
+		{"ABCDEFGHIJKLMN1!", []string{"too long by 4", "needs 2 more lowercase letters", "needs 2 more digits", "needs 1 more symbol"}},
+		{"aB123!?x", nil},
+		{"Abc123!?xyz", nil},
+	} {
+		if got := g.PolicyDiff(tt.password, policy); !slices.Equal(got, tt.want) {
+			t.Errorf("PolicyDiff(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+	if got := g.PolicyDiff("aB1", &Policy{MinUpper: 2, MinDigits: 1, MinSymbols: 1}); !slices.Equal(got, []string{"needs 1 more uppercase letter", "missing symbol"}) {
+		t.Errorf("PolicyDiff(%q) = %q", "aB1", got)
+	}
+	if got := g.PolicyDiff("a", nil); len(got) != 0 {
+		t.Errorf("PolicyDiff(nil policy) = %q, want no requirement", got)
+	}
+}
+
+func TestGenerateFromPattern(t *testing.T) {
+	g := NewGenerator(nil)
+	for range 200 {
+		pwd, err := g.GenerateFromPattern("UDDD-LLLL_S")
+		if err != nil {
+			t.Fatal(err)
+		}
+		runes := []rune(pwd)
+		if len(runes) != 11 || runes[4] != '-' || runes[9] != '_' {
+			t.Fatalf("GenerateFromPattern(%q) = %q, want the literals kept", "UDDD-LLLL_S", pwd)
+		}
+		for i, pool := range []string{g.upperLetters, g.digits, g.digits, g.digits, "-", g.lowerLetters, g.lowerLetters, g.lowerLetters, g.lowerLetters, "_", g.symbols} {
+			if !strings.ContainsRune(pool, runes[i]) {
+				t.Fatalf("GenerateFromPattern(%q) = %q: %q at position %d is not in %q", "UDDD-LLLL_S", pwd, runes[i], i, pool)
+			}
+		}
+	}
+
+	// Escaped tokens, other characters and a trailing backslash are literals
+	for pattern, want := range map[string]string{
+		`\L\U\D\S`: "LUDS",
+		`\\`:       `\`,
+		`id: \D`:   "id: D",
+		`x\`:       `x\`,
+		"":         "",
+		"é€":       "é€",
+	} {
+		if got, err := g.GenerateFromPattern(pattern); err != nil || got != want {
+			t.Errorf("GenerateFromPattern(%q) = %q, %v, want %q", pattern, got, err, want)
+		}
+	}
+	if pwd, err := g.GenerateFromPattern(`\DD`); err != nil || len(pwd) != 2 || pwd[0] != 'D' || !strings.ContainsRune(g.digits, rune(pwd[1])) {
+		t.Errorf("GenerateFromPattern(%q) = %q, %v, want D followed by a digit", `\DD`, pwd, err)
+	}
+}
+
+func TestGenerateResult(t *testing.T) {
+	g := NewGenerator(nil)
+	params := GenerateConfig{Length: 14, NumDigits: 3, NumSymbols: 2, AllowUppercase: true}
+	result, err := g.GenerateResult(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if utf8.RuneCountInString(result.Password) != 14 || result.Length != 14 || result.Digits != 3 || result.Symbols != 2 || result.EntropyBits != g.Entropy(params) {
+		t.Errorf("GenerateResult() = %+v, want 14 characters with 3 digits, 2 symbols and %v bits", result, g.Entropy(params))
+	}
+
+	// The length counts the characters, not the bytes
+	result = NewGenerator(&GeneratorInput{Symbols: "€"}).Result("ab1€", GenerateConfig{Length: 4, NumDigits: 1, NumSymbols: 1, AllowRepeat: true})
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`{"password":"ab1€","length":4,"digits":1,"symbols":1,"entropy_bits":%v}`, result.EntropyBits)
+	if string(data) != want {
+		t.Errorf("json.Marshal(result) = %s, want %s", data, want)
+	}
+	var decoded PasswordResult
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != result {
+		t.Errorf("json.Unmarshal() = %+v, %v, want %+v", decoded, err, result)
+	}
+}
+
+func TestStream(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, allowUpper := range []bool{false, true} {
+		pool := g.lowerLetters + g.digits + g.symbols
+		if allowUpper {
+			pool += g.upperLetters
+		}
+		buf := make([]byte, 10000)
+		if _, err := io.ReadFull(g.Stream(allowUpper), buf); err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[byte]int)
+		for _, c := range buf {
+			if !strings.ContainsRune(pool, rune(c)) {
+				t.Fatalf("Stream(%v) read %q, not in %q", allowUpper, c, pool)
+			}
+			counts[c]++
+		}
+		if len(counts) != len(pool) {
+			t.Errorf("Stream(%v) read %d different characters, want %d", allowUpper, len(counts), len(pool))
+		}
+		observed := make([]int, 0, len(counts))
+		for _, n := range counts {
+			observed = append(observed, n)
+		}
+		// 99.99th percentile of the chi-square distribution for about 90 degrees of freedom
+		if x := chiSquare(observed, float64(len(buf))/float64(len(pool))); x > 160 {
+			t.Errorf("Stream(%v) characters are not uniform: chi-square %v", allowUpper, x)
+		}
+	}
+
+	// Only the ASCII characters are streamed
+	g = NewGenerator(&GeneratorInput{LowerLetters: "aé", Digits: "1", Symbols: "€"})
+	buf := make([]byte, 200)
+	if _, err := io.ReadFull(g.Stream(false), buf); err != nil || strings.Trim(string(buf), "a1") != "" {
+		t.Errorf("Stream() read %q, %v, want only a and 1", buf, err)
+	}
+	g = NewGenerator(&GeneratorInput{LowerLetters: "é", Digits: "١", Symbols: "€"})
+	if n, err := g.Stream(false).Read(buf); n != 0 || !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("Stream(no ASCII character).Read() = %d, %v, want 0, %v", n, err, ErrEmptyPool)
+	}
+}
+
+func TestGeneratePIN(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, allowRepeat := range []bool{true, false} {
+		for range 200 {
+			pin, err := g.GeneratePIN(6, allowRepeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pin) != 6 || strings.Trim(pin, Digits) != "" {
+				t.Fatalf("GeneratePIN(6, %v) = %q, want 6 digits", allowRepeat, pin)
+			}
+			if !allowRepeat && distinctCount(pin) != 6 {
+				t.Fatalf("GeneratePIN(6, false) = %q repeats a digit", pin)
+			}
+		}
+	}
+	// All the digits once, and repeats beyond them
+	if pin, err := g.GeneratePIN(10, false); err != nil || distinctCount(pin) != 10 {
+		t.Errorf("GeneratePIN(10, false) = %q, %v, want the 10 digits", pin, err)
+	}
+	if _, err := g.GeneratePIN(11, false); !errors.Is(err, ErrDigitsExceedsAvailable) {
+		t.Errorf("GeneratePIN(11, false) error = %v, want %v", err, ErrDigitsExceedsAvailable)
+	}
+	if pin, err := g.GeneratePIN(20, true); err != nil || len(pin) != 20 {
+		t.Errorf("GeneratePIN(20, true) = %q, %v, want 20 digits", pin, err)
+	}
+	if _, err := g.GeneratePIN(0, true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GeneratePIN(0) error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	// The configured digits only
+	g = NewGenerator(&GeneratorInput{Digits: "2468"})
+	if pin, err := g.GeneratePIN(4, false); err != nil || strings.Trim(pin, "2468") != "" || distinctCount(pin) != 4 {
+		t.Errorf("GeneratePIN(4, false) = %q, %v, want the 4 configured digits", pin, err)
+	}
+}
+
+func TestPool(t *testing.T) {
+	g := NewGenerator(nil)
+	for allowUpper, want := range map[bool]string{false: LowerLetters + Digits + Symbols, true: LowerLetters + UpperLetters + Digits + Symbols} {
+		if pool := g.Pool(allowUpper); pool != want {
+			t.Errorf("Pool(%v) = %q, want %q", allowUpper, pool, want)
+		}
+		if n := g.PoolSize(allowUpper); n != utf8.RuneCountInString(want) {
+			t.Errorf("PoolSize(%v) = %d, want %d", allowUpper, n, utf8.RuneCountInString(want))
+		}
+	}
+
+	g = NewGenerator(&GeneratorInput{LowerLetters: "abc", UpperLetters: "ABC", Digits: "12", Symbols: "€!"})
+	if pool, n := g.Pool(true), g.PoolSize(true); pool != "abcABC12€!" || n != 10 {
+		t.Errorf("Pool(true), PoolSize(true) = %q, %d, want %q, 10", pool, n, "abcABC12€!")
+	}
+	if pool, n := g.Pool(false), g.PoolSize(false); pool != "abc12€!" || n != 7 {
+		t.Errorf("Pool(false), PoolSize(false) = %q, %d, want %q, 7", pool, n, "abc12€!")
+	}
+	// A character of several sets is counted once
+	g = NewGenerator(&GeneratorInput{LowerLetters: "abc", Digits: "12", Symbols: "a!"})
+	if pool, n := g.Pool(false), g.PoolSize(false); pool != "abc12!" || n != 6 {
+		t.Errorf("Pool(false), PoolSize(false) = %q, %d, want %q, 6", pool, n, "abc12!")
+	}
+}
@@ -0,0 +1,85 @@
+package passwordgenerator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Policy gathers the requirements a password must meet, e.g. the password
+// rules of a website. A zero value field is not a requirement.
+type Policy struct {
+	// MinLength is the minimal number of characters.
+	MinLength int
+	// MaxLength is the maximal number of characters.
+	MaxLength int
+	// MinLower is the minimal number of lowercase letters.
+	MinLower int
+	// MinUpper is the minimal number of uppercase letters.
+	MinUpper int
+	// MinDigits is the minimal number of digits.
+	MinDigits int
+	// MinSymbols is the minimal number of symbols.
+	MinSymbols int
+}
+
+/*
+Function which lists the requirements of a policy a password does not meet.
+	Method of Generator type
+	The characters are counted using the character sets of the generator.
+	The messages are meant to be shown to the user, e.g. "too short by 2",
+	"missing uppercase letter" or "needs 2 more digits".
+
+	Parameters:
+	-----------
+		password (string): password to check
+		p (*Policy): requirements of the password (none if nil)
+
+	Returns:
+	--------
+		[]string - unmet requirements, empty if the password meets the policy
+*/
+func (g *Generator) PolicyDiff(password string, p *Policy) []string {
+	var diff []string
+	if p == nil {
+		return diff
+	}
+
+	// Verify the length
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		diff = append(diff, fmt.Sprintf("too short by %d", p.MinLength-length))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		diff = append(diff, fmt.Sprintf("too long by %d", length-p.MaxLength))
+	}
+
+	// Verify the number of characters of each class
+	for _, class := range []struct {
+		name string
+		pool string
+		min  int
+	}{
+		{"lowercase letter", g.lowerLetters, p.MinLower},
+		{"uppercase letter", g.upperLetters, p.MinUpper},
+		{"digit", g.digits, p.MinDigits},
+		{"symbol", g.symbols, p.MinSymbols},
+	} {
+		count := 0
+		for _, r := range password {
+			if strings.ContainsRune(class.pool, r) {
+				count++
+			}
+		}
+		switch missing := class.min - count; {
+		case missing <= 0:
+		case count == 0 && class.min == 1:
+			diff = append(diff, "missing "+class.name)
+		case missing == 1:
+			diff = append(diff, "needs 1 more "+class.name)
+		default:
+			diff = append(diff, fmt.Sprintf("needs %d more %ss", missing, class.name))
+		}
+	}
+	return diff
+}
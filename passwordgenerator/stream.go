@@ -0,0 +1,64 @@
+package passwordgenerator
+
+import "unicode/utf8"
+
+// Stream is an endless stream of random password characters, e.g. to pipe
+// into another program. It implements io.Reader.
+type Stream struct {
+	gen  *Generator
+	pool []byte
+}
+
+/*
+Function which returns a stream of characters drawn from all the character sets of the generator.
+	Method of Generator type
+	Each byte read is drawn uniformly among the distinct letters, digits and
+	symbols of the generator. Only the ASCII characters are kept since every
+	byte must be a whole character. The stream reads the random source by
+	blocks, so it must not be shared between goroutines.
+
+	Parameters:
+	-----------
+		allowUpper (bool): include uppercase
+
+	Returns:
+	--------
+		*Stream - stream of characters
+*/
+func (g *Generator) Stream(allowUpper bool) *Stream {
+	letters, digits, symbols := g.pools(allowUpper)
+	var pool []byte
+	for _, r := range distinctChars(letters + digits + symbols) {
+		if r < utf8.RuneSelf {
+			pool = append(pool, byte(r))
+		}
+	}
+	return &Stream{gen: g.batch(0), pool: pool}
+}
+
+/*
+Function which fills a buffer with random characters.
+	Method of Stream type
+
+	Parameters:
+	-----------
+		p ([]byte): buffer to fill
+
+	Returns:
+	--------
+		int, error - number of bytes written (len(p) unless an error occurred) and the error if the characters were not drawn
+			Note: ErrEmptyPool is returned if the character sets have no ASCII character
+*/
+func (s *Stream) Read(p []byte) (int, error) {
+	if len(s.pool) == 0 {
+		return 0, ErrEmptyPool
+	}
+	for i := range p {
+		n, err := s.gen.randomInt(len(s.pool))
+		if err != nil {
+			return i, err
+		}
+		p[i] = s.pool[n]
+	}
+	return len(p), nil
+}
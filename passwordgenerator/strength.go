@@ -0,0 +1,76 @@
+package passwordgenerator
+
+import "fmt"
+
+// Strength is the level of strength of a password, from its entropy.
+type Strength int
+
+// Levels of strength, from the weakest to the strongest.
+const (
+	Weak Strength = iota
+	Fair
+	Strong
+	VeryStrong
+)
+
+// Entropy thresholds in bits from which a password reaches a level of
+// strength. They can be changed to follow another policy.
+var (
+	// FairEntropyBits is the entropy from which a password is Fair.
+	FairEntropyBits = 28.0
+	// StrongEntropyBits is the entropy from which a password is Strong.
+	StrongEntropyBits = 50.0
+	// VeryStrongEntropyBits is the entropy above which a password is VeryStrong.
+	VeryStrongEntropyBits = 70.0
+)
+
+/*
+Function which returns the name of the level of strength.
+	Method of Strength type
+
+	Returns:
+	--------
+		string - name of the level, e.g. "very strong"
+*/
+func (s Strength) String() string {
+	switch s {
+	case Weak:
+		return "weak"
+	case Fair:
+		return "fair"
+	case Strong:
+		return "strong"
+	case VeryStrong:
+		return "very strong"
+	}
+	return fmt.Sprintf("Strength(%d)", int(s))
+}
+
+/*
+Function which classifies the strength of the passwords generated with the given parameters.
+	Method of Generator type
+	The entropy given by Entropy is compared with FairEntropyBits,
+	StrongEntropyBits and VeryStrongEntropyBits: below 28 bits a password is
+	Weak, from 28 bits Fair, from 50 bits Strong and above 70 bits VeryStrong
+	with the default thresholds.
+
+	Parameters:
+	-----------
+		cfg (GenerateConfig): parameters of the password
+
+	Returns:
+	--------
+		Strength - level of strength (Weak if no password can be generated with these parameters)
+*/
+func (g *Generator) ClassifyStrength(cfg GenerateConfig) Strength {
+	bits := g.Entropy(cfg)
+	switch {
+	case bits > VeryStrongEntropyBits:
+		return VeryStrong
+	case bits >= StrongEntropyBits:
+		return Strong
+	case bits >= FairEntropyBits:
+		return Fair
+	}
+	return Weak
+}
@@ -0,0 +1,37 @@
+package passwordgenerator
+
+import "testing"
+
+func TestClassifyStrength(t *testing.T) {
+	// Each letter drawn from 2 letters brings exactly 1 bit
+	g := NewGenerator(&GeneratorInput{LowerLetters: "ab"})
+	for _, tt := range []struct {
+		length int
+		want   Strength
+	}{
+		{1, Weak},
+		{27, Weak},
+		{28, Fair},
+		{49, Fair},
+		{50, Strong},
+		{70, Strong},
+		{71, VeryStrong},
+	} {
+		cfg := GenerateConfig{Length: tt.length, AllowRepeat: true}
+		if got := g.ClassifyStrength(cfg); got != tt.want {
+			t.Errorf("ClassifyStrength(%d bits) = %v, want %v", tt.length, got, tt.want)
+		}
+	}
+	// No password can be generated
+	if got := g.ClassifyStrength(GenerateConfig{Length: 3}); got != Weak {
+		t.Errorf("ClassifyStrength(impossible) = %v, want %v", got, Weak)
+	}
+}
+
+func TestStrengthString(t *testing.T) {
+	for s, want := range map[Strength]string{Weak: "weak", Fair: "fair", Strong: "strong", VeryStrong: "very strong", 7: "Strength(7)"} {
+		if got := s.String(); got != want {
+			t.Errorf("Strength(%d).String() = %q, want %q", int(s), got, want)
+		}
+	}
+}
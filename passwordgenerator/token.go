@@ -0,0 +1,60 @@
+package passwordgenerator
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// TokenEncoding is the encoding of the random bytes of a token.
+type TokenEncoding int
+
+// Encodings of the tokens returned by GenerateToken.
+const (
+	// Base64URL is the URL-safe base64 encoding without padding (RFC 4648).
+	Base64URL TokenEncoding = iota
+	// Base32 is the standard base32 encoding without padding (RFC 4648).
+	Base32
+	// Hex is the lowercase hexadecimal encoding.
+	Hex
+)
+
+/*
+Function which generates a token made of random bytes, e.g. an API token
+	Method of Generator type
+	The bytes are read from the random source of the generator and encoded
+	with the given encoding. None of the encodings needs escaping in a URL.
+
+	Parameters:
+	-----------
+		byteLength (int): number of random bytes (must be positive)
+		encoding (TokenEncoding): encoding of the bytes
+
+	Returns:
+	--------
+		string, error - token and the error if the token was not generated
+*/
+func (g *Generator) GenerateToken(byteLength int, encoding TokenEncoding) (string, error) {
+	if byteLength < 1 {
+		return "", fmt.Errorf("%w: byteLength must be positive", ErrInvalidArgument)
+	}
+	var encode func([]byte) string
+	switch encoding {
+	case Base64URL:
+		encode = base64.RawURLEncoding.EncodeToString
+	case Base32:
+		encode = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString
+	case Hex:
+		encode = hex.EncodeToString
+	default:
+		return "", fmt.Errorf("%w: unknown token encoding %d", ErrInvalidArgument, int(encoding))
+	}
+
+	b := make([]byte, byteLength)
+	if _, err := io.ReadFull(g.reader, b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
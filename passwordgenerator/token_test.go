@@ -0,0 +1,55 @@
+package passwordgenerator
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateToken(t *testing.T) {
+	g := NewGenerator(nil)
+	for _, tt := range []struct {
+		encoding TokenEncoding
+		decode   func(string) ([]byte, error)
+	}{
+		{Base64URL, base64.RawURLEncoding.DecodeString},
+		{Base32, base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString},
+		{Hex, hex.DecodeString},
+	} {
+		for _, n := range []int{1, 16, 31, 32} {
+			token, err := g.GenerateToken(n, tt.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := tt.decode(token)
+			if err != nil {
+				t.Fatalf("decoding token %q (encoding %d): %v", token, tt.encoding, err)
+			}
+			if len(b) != n {
+				t.Errorf("token %q (encoding %d) decodes to %d bytes, want %d", token, tt.encoding, len(b), n)
+			}
+		}
+	}
+
+	// The bytes 0xfb and 0xff are encoded with '+' and '/' in standard base64
+	g = NewGenerator(&GeneratorInput{Reader: strings.NewReader(strings.Repeat("\xfb\xff", 16))})
+	token, err := g.GenerateToken(32, Base64URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("base64url token %q contains '+', '/' or '='", token)
+	}
+
+	for _, tt := range []struct {
+		n        int
+		encoding TokenEncoding
+	}{{0, Hex}, {16, TokenEncoding(3)}} {
+		if _, err := g.GenerateToken(tt.n, tt.encoding); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GenerateToken(%d, %d) error = %v, want %v", tt.n, tt.encoding, err, ErrInvalidArgument)
+		}
+	}
+}